@@ -1,13 +1,13 @@
 package installer
 
 import (
-	"embed"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
+
+	"github.com/lucas/installer/semver"
 )
 
 // GetInstallDir returns the default installation directory.
@@ -35,39 +35,23 @@ func ParseVersion(content string) string {
 	return word[0]
 }
 
-// CompareVersions compares semver strings like "v1.4.0". Returns -1, 0, or 1.
-func CompareVersions(a, b string) int {
-	pa := parseParts(a)
-	pb := parseParts(b)
-	for i := 0; i < 3; i++ {
-		if pa[i] < pb[i] {
-			return -1
-		}
-		if pa[i] > pb[i] {
-			return 1
-		}
-	}
-	return 0
-}
-
-func parseParts(v string) [3]int {
-	v = strings.TrimPrefix(v, "v")
-	parts := strings.SplitN(v, ".", 3)
-	var nums [3]int
-	for i, p := range parts {
-		if i >= 3 {
-			break
-		}
-		n, _ := strconv.Atoi(p)
-		nums[i] = n
-	}
-	return nums
+// CompareVersions compares SemVer 2.0 versions like "v1.4.0" or
+// "v1.5.0-rc.1" by precedence. Returns -1, 0, or 1, or an error if either
+// is not a valid version.
+func CompareVersions(a, b string) (int, error) {
+	return semver.Compare(a, b)
 }
 
 // ExistingInstall represents a previously installed DevLauncher installation.
 type ExistingInstall struct {
-	Dir     string
-	Version string
+	Dir      string
+	Version  string
+	Manifest *Manifest // nil for installations made before manifests existed
+	Modified []string  // Installed files changed since (paths relative to Dir)
+	Missing  []string  // Installed files deleted since
+	// ManifestErr is set when manifest.tsv exists but cannot be read; the
+	// installation is then handled as one without manifest.
+	ManifestErr error
 }
 
 // DetectExistingInstall checks if an installation already exists at installDir.
@@ -80,14 +64,22 @@ func DetectExistingInstall(installDir string) (*ExistingInstall, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ExistingInstall{
+	existing := &ExistingInstall{
 		Dir:     installDir,
 		Version: ParseVersion(string(data)),
-	}, nil
+	}
+	manifest, err := ReadManifest(installDir)
+	if err != nil {
+		existing.ManifestErr = err
+	} else if manifest != nil {
+		existing.Manifest = manifest
+		existing.Modified, existing.Missing = manifest.Drift(installDir)
+	}
+	return existing, nil
 }
 
 // CountAssets counts files in the assets/ embed (excluding .gitkeep).
-func CountAssets(fsys embed.FS) int {
+func CountAssets(fsys fs.FS) int {
 	count := 0
 	_ = fs.WalkDir(fsys, "assets", func(path string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
@@ -103,34 +95,29 @@ func CountAssets(fsys embed.FS) int {
 	return count
 }
 
-// ExtractAssets extracts all embedded assets to destDir.
-// progress callback is called for each file extracted.
-func ExtractAssets(fsys embed.FS, destDir string, progress func(current, total int, filename string)) error {
+// ExtractAssets extracts all embedded assets to destDir and records them in
+// its manifest. Scripts the user modified since the previous install are
+// kept; if the new version changes them too, its copy is written next to
+// them as <path>.new (see PlanUpgrade). Files of the previous manifest the
+// new version no longer ships are deleted unless modified. progress callback is called for
+// each file extracted.
+func ExtractAssets(fsys fs.FS, destDir string, progress func(current, total int, filename string)) error {
 	total := CountAssets(fsys)
 	current := 0
 
-	return fs.WalkDir(fsys, "assets", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		base := filepath.Base(path)
-		if base == ".gitkeep" || base == "placeholder" {
-			return nil
-		}
-
-		destPath := mapAssetPath(path, destDir)
-		if destPath == "" {
-			return nil
-		}
+	prev := previousEntries(destDir)
+	version := ""
+	if data, err := fs.ReadFile(fsys, "assets/VERSION.txt"); err == nil {
+		version = ParseVersion(string(data))
+	}
+	manifest := &Manifest{}
 
+	err := walkAssets(fsys, destDir, func(path, destPath, rel string) error {
 		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 			return err
 		}
 
-		data, err := fsys.ReadFile(path)
+		data, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return err
 		}
@@ -139,9 +126,21 @@ func ExtractAssets(fsys embed.FS, destDir string, progress func(current, total i
 		if isExecutable(path) {
 			perm = 0755
 		}
-		if err := os.WriteFile(destPath, data, perm); err != nil {
-			return err
-		}
+		switch planFile(destPath, rel, data, prev) {
+		case actionKeep:
+		case actionConflict:
+			if err := os.WriteFile(destPath+NewSuffix, data, perm); err != nil {
+				return err
+			}
+			manifest.Entries = append(manifest.Entries, newManifestEntry(rel+NewSuffix, data, perm, version))
+		default:
+			if err := os.WriteFile(destPath, data, perm); err != nil {
+				return err
+			}
+		}
+		// Kept scripts are recorded with the shipped copy, so they still
+		// show as modified next time
+		manifest.Entries = append(manifest.Entries, newManifestEntry(rel, data, perm, version))
 
 		current++
 		if progress != nil {
@@ -149,6 +148,20 @@ func ExtractAssets(fsys embed.FS, destDir string, progress func(current, total i
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Files the new version dropped go unless the user modified them
+	shipped := make(map[string]bool, len(manifest.Entries))
+	for _, e := range manifest.Entries {
+		shipped[e.Path] = true
+	}
+	unchanged, _ := retiredFiles(destDir, prev, shipped)
+	if err := removeRetired(destDir, unchanged); err != nil {
+		return err
+	}
+	return WriteManifest(destDir, manifest)
 }
 
 // mapAssetPath converts an embedded path to the destination path.
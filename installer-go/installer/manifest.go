@@ -0,0 +1,259 @@
+package installer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ManifestFile lists every file ExtractAssets wrote, so upgrades can tell
+// shipped files from user changes and uninstalling removes only our files.
+// It is tab-separated text, one file per line, so the generated uninstaller
+// scripts can read it without extra tools:
+//
+//	# DevLauncher install manifest: sha256, size, mode, version, path (tab-separated)
+//	<sha256>	<size>	<mode>	<version>	<path relative to the install dir>
+//
+// No field is ever empty (an unknown version is written as "-"): the shell
+// uninstaller splits lines with read, which collapses consecutive tabs.
+const ManifestFile = "manifest.tsv"
+
+const noVersion = "-"
+
+const manifestHeader = "# DevLauncher install manifest: sha256, size, mode, version, path (tab-separated)"
+
+// generatedFiles are written by the installer itself, outside the manifest.
+var generatedFiles = []string{ManifestFile, "uninstaller.sh", "uninstaller.ps1"}
+
+// ManifestEntry describes one installed file.
+type ManifestEntry struct {
+	Path    string // Slash-separated, relative to the install dir
+	Size    int64
+	SHA256  string
+	Mode    fs.FileMode
+	Version string // Version of the installer that wrote it
+}
+
+// Manifest is the list of installed files.
+type Manifest struct {
+	Entries []ManifestEntry
+}
+
+func newManifestEntry(path string, data []byte, mode fs.FileMode, version string) ManifestEntry {
+	sum := sha256.Sum256(data)
+	return ManifestEntry{
+		Path:    path,
+		Size:    int64(len(data)),
+		SHA256:  hex.EncodeToString(sum[:]),
+		Mode:    mode,
+		Version: version,
+	}
+}
+
+// WriteManifest saves the manifest into installDir.
+func WriteManifest(installDir string, m *Manifest) error {
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+
+	var sb strings.Builder
+	sb.WriteString(manifestHeader + "\n")
+	for _, e := range m.Entries {
+		version := e.Version
+		if version == "" {
+			version = noVersion
+		}
+		fmt.Fprintf(&sb, "%s\t%d\t%04o\t%s\t%s\n", e.SHA256, e.Size, e.Mode.Perm(), version, e.Path)
+	}
+	return os.WriteFile(filepath.Join(installDir, ManifestFile), []byte(sb.String()), 0644)
+}
+
+// ReadManifest loads the manifest of installDir. It returns nil without
+// error for installations made before manifests existed.
+func ReadManifest(installDir string) (*Manifest, error) {
+	f, err := os.Open(filepath.Join(installDir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := &Manifest{}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("%s:%d: línea no válida", ManifestFile, lineNum)
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: tamaño no válido", ManifestFile, lineNum)
+		}
+		mode, err := strconv.ParseUint(fields[2], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: modo no válido", ManifestFile, lineNum)
+		}
+		if fields[3] == noVersion {
+			fields[3] = ""
+		}
+		m.Entries = append(m.Entries, ManifestEntry{
+			SHA256:  fields[0],
+			Size:    size,
+			Mode:    fs.FileMode(mode),
+			Version: fields[3],
+			Path:    fields[4],
+		})
+	}
+	return m, scanner.Err()
+}
+
+// ManifestWarning describes an unreadable manifest, which is handled as an
+// installation made before manifests.
+func ManifestWarning(err error) string {
+	return fmt.Sprintf("no se pudo leer %s (%v); se trata como una instalación sin manifiesto", ManifestFile, err)
+}
+
+// fileStatus compares an installed file with its manifest entry.
+type fileStatus int
+
+const (
+	fileUnchanged fileStatus = iota
+	fileModified
+	fileMissing
+)
+
+func (e ManifestEntry) status(installDir string) fileStatus {
+	path := filepath.Join(installDir, filepath.FromSlash(e.Path))
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileMissing
+	}
+	if info.Size() != e.Size {
+		return fileModified
+	}
+	sum, err := fileSHA256(path)
+	if err != nil || sum != e.SHA256 {
+		return fileModified
+	}
+	return fileUnchanged
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Drift checks the installed files against the manifest and returns the
+// paths changed or deleted since they were installed.
+func (m *Manifest) Drift(installDir string) (modified, missing []string) {
+	for _, e := range m.Entries {
+		switch e.status(installDir) {
+		case fileModified:
+			modified = append(modified, e.Path)
+		case fileMissing:
+			missing = append(missing, e.Path)
+		}
+	}
+	return modified, missing
+}
+
+// RemoveOwnedFiles deletes the files of the manifest that are unchanged,
+// the files the installer generated and the folders left empty. Files the
+// user modified or added are kept and returned (relative paths). The
+// running executable is skipped, as Windows cannot delete it.
+func RemoveOwnedFiles(installDir string, m *Manifest) (removed int, kept []string, err error) {
+	self, _ := os.Executable()
+	remove := func(path string) error {
+		if self != "" && samePath(path, self) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	for _, e := range m.Entries {
+		path := filepath.Join(installDir, filepath.FromSlash(e.Path))
+		switch e.status(installDir) {
+		case fileModified:
+			kept = append(kept, e.Path)
+			continue
+		case fileMissing:
+			continue
+		}
+		if err := remove(path); err != nil {
+			return removed, kept, err
+		}
+		removed++
+	}
+	for _, name := range generatedFiles {
+		if err := remove(filepath.Join(installDir, name)); err != nil {
+			return removed, kept, err
+		}
+	}
+
+	// Whatever is left and not in the manifest was added by the user
+	owned := map[string]bool{}
+	for _, e := range m.Entries {
+		owned[e.Path] = true
+	}
+	var dirs []string
+	_ = filepath.WalkDir(installDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(installDir, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		} else if !owned[rel] {
+			kept = append(kept, rel)
+		}
+		return nil
+	})
+
+	// Deepest folders first; only the empty ones go
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		_ = os.Remove(dir)
+	}
+	_ = os.Remove(installDir)
+
+	sort.Strings(kept)
+	return removed, kept, nil
+}
+
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,135 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	want := &Manifest{Entries: []ManifestEntry{
+		newManifestEntry("scripts/linux/b.sh", []byte("echo b\n"), 0755, "v1.2.0"),
+		newManifestEntry("VERSION.txt", []byte("v1.2.0\n"), 0644, "v1.2.0"),
+		newManifestEntry("static/sin version.txt", []byte("x"), 0644, ""),
+	}}
+	if err := WriteManifest(dir, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// WriteManifest sorts the entries by path
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadManifest = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	m, err := ReadManifest(t.TempDir())
+	if m != nil || err != nil {
+		t.Errorf("ReadManifest = %v, %v; want nil, nil", m, err)
+	}
+}
+
+func TestReadManifestInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"truncated": "abc\t3\t0644\tv1.0.0\n",
+		"size":      "abc\tx\t0644\tv1.0.0\tVERSION.txt\n",
+		"mode":      "abc\t3\t9999\tv1.0.0\tVERSION.txt\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, ManifestFile), manifestHeader+"\n"+content)
+			if _, err := ReadManifest(dir); err == nil {
+				t.Error("ReadManifest accepted an invalid line")
+			}
+		})
+	}
+}
+
+func TestDetectExistingInstallUnreadableManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "VERSION.txt"), "v1.0.0\n")
+	writeTestFile(t, filepath.Join(dir, ManifestFile), "abc\t3\n")
+
+	existing, err := DetectExistingInstall(dir)
+	if err != nil {
+		t.Fatalf("DetectExistingInstall: %v", err)
+	}
+	if existing == nil || existing.Version != "v1.0.0" {
+		t.Fatalf("existing = %+v", existing)
+	}
+	if existing.Manifest != nil || existing.ManifestErr == nil {
+		t.Errorf("Manifest = %v, ManifestErr = %v; want a legacy install with the error", existing.Manifest, existing.ManifestErr)
+	}
+}
+
+func TestDriftAndRemoveOwnedFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"VERSION.txt":              "v1.0.0\n",
+		"scripts/linux/same.sh":    "same\n",
+		"scripts/linux/edited.sh":  "original\n",
+		"scripts/linux/deleted.sh": "deleted\n",
+		"static/only/file.txt":     "static\n",
+	}
+	m := &Manifest{}
+	for path, content := range files {
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(path)), content)
+		m.Entries = append(m.Entries, newManifestEntry(path, []byte(content), 0644, "v1.0.0"))
+	}
+	if err := WriteManifest(dir, m); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "uninstaller.sh"), "#!/bin/bash\n")
+	writeTestFile(t, filepath.Join(dir, "scripts/linux/edited.sh"), "mine\n")
+	writeTestFile(t, filepath.Join(dir, "scripts/mine/added.sh"), "added\n")
+	if err := os.Remove(filepath.Join(dir, "scripts/linux/deleted.sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	modified, missing := m.Drift(dir)
+	if !reflect.DeepEqual(modified, []string{"scripts/linux/edited.sh"}) {
+		t.Errorf("modified = %v", modified)
+	}
+	if !reflect.DeepEqual(missing, []string{"scripts/linux/deleted.sh"}) {
+		t.Errorf("missing = %v", missing)
+	}
+
+	removed, kept, err := RemoveOwnedFiles(dir, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("removed = %d, want 3", removed)
+	}
+	wantKept := []string{"scripts/linux/edited.sh", "scripts/mine/added.sh"}
+	if !reflect.DeepEqual(kept, wantKept) {
+		t.Errorf("kept = %v, want %v", kept, wantKept)
+	}
+
+	for _, path := range []string{"VERSION.txt", "scripts/linux/same.sh", "static", ManifestFile, "uninstaller.sh"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", path)
+		}
+	}
+	for _, path := range wantKept {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("%s was removed", path)
+		}
+	}
+}
@@ -88,13 +88,12 @@ alias dl="devlauncher"
 
 devscript() {
     if [ -z "$1" ]; then
-        echo "Uso: devscript <nombre_script>"
+        echo "Uso: devscript <categoria/script | nombre_script> [args...]"
         return 1
     fi
-    local script=$(find "$DEVSCRIPTS_ROOT/scripts" -type f -name "$1" ! -path "*/lib/*" | head -n1)
-    if [ -z "$script" ]; then echo "Script no encontrado: $1"; return 1; fi
-    echo "Ejecutando: $script"
-    bash "$script" "${@:2}"
+    local script="$1"
+    shift
+    "$DEVSCRIPTS_ROOT/launcher" run "$script" -- "$@"
 }
 # End DevScripts Installer`
 }
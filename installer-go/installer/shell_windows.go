@@ -153,17 +153,10 @@ function devscript {
         [string[]]$Arguments
     )
     if (-not $ScriptName) {
-        Write-Host "Uso: devscript <nombre_script>"
+        Write-Host "Uso: devscript <categoria/script | nombre_script> [args...]"
         return
     }
-    $searchPath = Join-Path "$env:DEVSCRIPTS_ROOT" "scripts\win"
-    $script = Get-ChildItem -Path $searchPath -Recurse -File -Filter $ScriptName -ErrorAction SilentlyContinue |
-               Where-Object { $_.DirectoryName -notmatch '\\lib$' } |
-               Select-Object -First 1
-    if (-not $script) { Write-Host "Script no encontrado: $ScriptName"; return }
-    Write-Host "Ejecutando: $($script.FullName)"
-    if ($script.Extension -eq '.ps1') { & $script.FullName @Arguments }
-    elseif ($script.Extension -eq '.bat') { cmd.exe /c $script.FullName @Arguments }
+    & "$env:DEVSCRIPTS_ROOT\launcher.exe" run $ScriptName -- @Arguments
 }
 # End DevScripts Installer`
 }
@@ -0,0 +1,231 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StagingDir is where a new installation is prepared before replacing installDir.
+func StagingDir(installDir string) string {
+	return filepath.Clean(installDir) + ".staging"
+}
+
+// BackupDir keeps the previous installation after an upgrade, for Rollback.
+func BackupDir(installDir string) string {
+	return filepath.Clean(installDir) + ".backup"
+}
+
+// ErrRestoreFailed is returned by Commit when the swap failed and the
+// previous installation could not be put back. It is left in BackupDir and
+// the staging dir is kept, so RecoverInterruptedSwap restores it next time.
+var ErrRestoreFailed = errors.New("no se pudo restaurar la instalación anterior")
+
+// Staging is an installation being prepared next to the install dir, so a
+// failed extraction never leaves the install dir half written.
+type Staging struct {
+	InstallDir string
+	Dir        string
+}
+
+// BeginStaging creates the staging directory as a copy of the current
+// installation, so files the user added or modified carry over.
+func BeginStaging(installDir string) (*Staging, error) {
+	s := &Staging{InstallDir: installDir, Dir: StagingDir(installDir)}
+	if err := RecoverInterruptedSwap(installDir); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(s.Dir); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(installDir); err == nil {
+		if err := copyTree(installDir, s.Dir); err != nil {
+			s.Abort()
+			return nil, fmt.Errorf("no se pudo preparar la instalación: %w", err)
+		}
+		return s, nil
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Abort discards the staging directory; the install dir is left untouched.
+func (s *Staging) Abort() {
+	_ = os.RemoveAll(s.Dir)
+}
+
+// Commit validates the staging directory and swaps it with the install dir,
+// which becomes the backup. If the swap fails the previous installation is
+// put back.
+func (s *Staging) Commit() error {
+	if err := validateInstall(s.Dir); err != nil {
+		s.Abort()
+		return fmt.Errorf("la instalación preparada no es válida: %w", err)
+	}
+
+	backup := BackupDir(s.InstallDir)
+	_, statErr := os.Stat(s.InstallDir)
+	hadPrevious := statErr == nil
+	if hadPrevious {
+		if err := os.RemoveAll(backup); err != nil {
+			s.Abort()
+			return err
+		}
+		if err := os.Rename(s.InstallDir, backup); err != nil {
+			s.Abort()
+			return fmt.Errorf("no se pudo reemplazar %s: %w", s.InstallDir, err)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(s.InstallDir), 0755); err != nil {
+		s.Abort()
+		return err
+	}
+
+	if err := os.Rename(s.Dir, s.InstallDir); err != nil {
+		if !hadPrevious {
+			s.Abort()
+			return fmt.Errorf("no se pudo activar la instalación: %w", err)
+		}
+		if restoreErr := os.Rename(backup, s.InstallDir); restoreErr != nil {
+			return fmt.Errorf("no se pudo activar la instalación: %w; %w (sigue en %s): %v", err, ErrRestoreFailed, backup, restoreErr)
+		}
+		s.Abort()
+		return fmt.Errorf("no se pudo activar la instalación (se restauró la anterior): %w", err)
+	}
+	return nil
+}
+
+// Rollback restores the installation kept by the last upgrade and returns
+// its version. The current installation is discarded.
+func Rollback(installDir string) (string, error) {
+	backup := BackupDir(installDir)
+	if _, err := os.Stat(backup); err != nil {
+		return "", fmt.Errorf("no hay una instalación anterior en %s", backup)
+	}
+	version := ""
+	if data, err := os.ReadFile(filepath.Join(backup, "VERSION.txt")); err == nil {
+		version = ParseVersion(string(data))
+	}
+
+	discarded := StagingDir(installDir)
+	if err := os.RemoveAll(discarded); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(installDir); err == nil {
+		if err := os.Rename(installDir, discarded); err != nil {
+			return "", fmt.Errorf("no se pudo apartar %s: %w", installDir, err)
+		}
+	}
+	if err := os.Rename(backup, installDir); err != nil {
+		_ = os.Rename(discarded, installDir)
+		return "", err
+	}
+	_ = os.RemoveAll(discarded)
+	return version, nil
+}
+
+// RemoveBackup deletes the staging and backup directories of installDir.
+func RemoveBackup(installDir string) error {
+	if err := os.RemoveAll(StagingDir(installDir)); err != nil {
+		return err
+	}
+	return os.RemoveAll(BackupDir(installDir))
+}
+
+// RecoverInterruptedSwap puts the backup back when a previous Commit was
+// interrupted between moving the install dir away and activating staging.
+// A leftover staging dir is the evidence: without it a missing install dir
+// was removed on purpose and the backup is left alone.
+// Call it before DetectExistingInstall.
+func RecoverInterruptedSwap(installDir string) error {
+	if _, err := os.Stat(installDir); !os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(StagingDir(installDir)); err != nil {
+		return nil
+	}
+	backup := BackupDir(installDir)
+	if _, err := os.Stat(backup); err != nil {
+		return nil
+	}
+	if err := os.Rename(backup, installDir); err != nil {
+		return fmt.Errorf("no se pudo restaurar %s: %w", backup, err)
+	}
+	return os.RemoveAll(StagingDir(installDir))
+}
+
+// validateInstall checks that every file of the manifest was written.
+// Scripts may differ, as the user's modified copies are kept.
+func validateInstall(dir string) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return fmt.Errorf("falta %s", ManifestFile)
+	}
+	for _, e := range m.Entries {
+		switch e.status(dir) {
+		case fileMissing:
+			return fmt.Errorf("falta %s", e.Path)
+		case fileModified:
+			if !strings.HasPrefix(e.Path, "scripts/") {
+				return fmt.Errorf("%s no coincide con el manifiesto", e.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// copyTree copies src into dst keeping permissions and symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,256 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testAssets builds an embedded assets tree with the given VERSION.txt and
+// files (paths relative to assets/).
+func testAssets(version string, files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{"assets/VERSION.txt": {Data: []byte(version + "\n")}}
+	for rel, content := range files {
+		fsys["assets/"+rel] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+// install runs the same steps as the installer: stage, extract, commit.
+func install(t *testing.T, dir string, assets fstest.MapFS) {
+	t.Helper()
+	staging, err := BeginStaging(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractAssets(assets, staging.Dir, nil); err != nil {
+		staging.Abort()
+		t.Fatal(err)
+	}
+	if err := staging.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFailedExtractionLeavesInstallUntouched(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".devlauncher")
+	install(t, dir, testAssets("v1.0.0", map[string]string{"scripts/a.sh": "echo v1\n"}))
+	// A file of the user where the new version puts a folder
+	writeTestFile(t, filepath.Join(dir, "scripts", "tools"), "mine\n")
+
+	staging, err := BeginStaging(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ExtractAssets(testAssets("v2.0.0", map[string]string{
+		"scripts/a.sh":       "echo v2\n",
+		"scripts/tools/b.sh": "echo b\n",
+	}), staging.Dir, nil)
+	if err == nil {
+		t.Fatal("ExtractAssets succeeded over a file in place of a folder")
+	}
+	staging.Abort()
+
+	if _, err := os.Stat(staging.Dir); !os.IsNotExist(err) {
+		t.Error("staging dir was not removed")
+	}
+	if _, err := os.Stat(BackupDir(dir)); !os.IsNotExist(err) {
+		t.Error("a backup was made without an upgrade")
+	}
+	if got := readTestFile(t, filepath.Join(dir, "VERSION.txt")); got != "v1.0.0\n" {
+		t.Errorf("VERSION.txt = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "scripts", "a.sh")); got != "echo v1\n" {
+		t.Errorf("scripts/a.sh = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "scripts", "tools")); got != "mine\n" {
+		t.Errorf("scripts/tools = %q", got)
+	}
+}
+
+func TestCommitAndRollback(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".devlauncher")
+	install(t, dir, testAssets("v1.0.0", map[string]string{"scripts/a.sh": "echo v1\n"}))
+	if _, err := os.Stat(BackupDir(dir)); !os.IsNotExist(err) {
+		t.Error("first install made a backup")
+	}
+	writeTestFile(t, filepath.Join(dir, "scripts", "mine.sh"), "echo mine\n")
+
+	install(t, dir, testAssets("v2.0.0", map[string]string{"scripts/a.sh": "echo v2\n"}))
+	if got := readTestFile(t, filepath.Join(dir, "scripts", "a.sh")); got != "echo v2\n" {
+		t.Errorf("after upgrade scripts/a.sh = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "scripts", "mine.sh")); got != "echo mine\n" {
+		t.Errorf("the user's script did not carry over: %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(BackupDir(dir), "VERSION.txt")); got != "v1.0.0\n" {
+		t.Errorf("backup VERSION.txt = %q", got)
+	}
+	if _, err := os.Stat(StagingDir(dir)); !os.IsNotExist(err) {
+		t.Error("staging dir left behind")
+	}
+
+	version, err := Rollback(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.0.0" {
+		t.Errorf("Rollback = %q, want v1.0.0", version)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "scripts", "a.sh")); got != "echo v1\n" {
+		t.Errorf("after rollback scripts/a.sh = %q", got)
+	}
+	for _, leftover := range []string{BackupDir(dir), StagingDir(dir)} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", leftover)
+		}
+	}
+	if _, err := Rollback(dir); err == nil {
+		t.Error("a second Rollback succeeded without a backup")
+	}
+}
+
+func TestCommitRestoresPreviousInstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".devlauncher")
+	install(t, dir, testAssets("v1.0.0", map[string]string{"scripts/a.sh": "echo v1\n"}))
+
+	// A staging dir inside the install dir moves away with it, so
+	// activating it fails after the install dir became the backup
+	staging := &Staging{InstallDir: dir, Dir: filepath.Join(dir, "next")}
+	if err := ExtractAssets(testAssets("v2.0.0", map[string]string{"scripts/a.sh": "echo v2\n"}), staging.Dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	err := staging.Commit()
+	if err == nil {
+		t.Fatal("Commit succeeded")
+	}
+	if errors.Is(err, ErrRestoreFailed) || !strings.Contains(err.Error(), "se restauró la anterior") {
+		t.Fatalf("Commit = %v, want the previous install restored", err)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "VERSION.txt")); got != "v1.0.0\n" {
+		t.Errorf("VERSION.txt = %q", got)
+	}
+	if _, err := os.Stat(staging.Dir); !os.IsNotExist(err) {
+		t.Error("staging dir was not removed")
+	}
+	if _, err := os.Stat(BackupDir(dir)); !os.IsNotExist(err) {
+		t.Error("backup left behind after restoring it")
+	}
+}
+
+func TestValidateInstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(dir string)
+		wantErr string
+	}{
+		{name: "complete", edit: func(string) {}},
+		{name: "modified script", edit: func(dir string) {
+			writeTestFile(t, filepath.Join(dir, "scripts", "a.sh"), "echo mine\n")
+		}},
+		{name: "missing file", wantErr: "falta scripts/a.sh", edit: func(dir string) {
+			os.Remove(filepath.Join(dir, "scripts", "a.sh"))
+		}},
+		{name: "truncated static file", wantErr: "static/s.txt", edit: func(dir string) {
+			writeTestFile(t, filepath.Join(dir, "static", "s.txt"), "")
+		}},
+		{name: "no manifest", wantErr: "falta " + ManifestFile, edit: func(dir string) {
+			os.Remove(filepath.Join(dir, ManifestFile))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assets := testAssets("v1.0.0", map[string]string{"scripts/a.sh": "echo v1\n", "static/s.txt": "static\n"})
+			if err := ExtractAssets(assets, dir, nil); err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(dir)
+			err := validateInstall(dir)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("validateInstall: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("validateInstall = %v, want an error about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommitRejectsHalfWrittenStaging(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".devlauncher")
+	install(t, dir, testAssets("v1.0.0", map[string]string{"scripts/a.sh": "echo v1\n"}))
+
+	staging, err := BeginStaging(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractAssets(testAssets("v2.0.0", map[string]string{"scripts/a.sh": "echo v2\n", "scripts/b.sh": "echo b\n"}), staging.Dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(staging.Dir, "scripts", "b.sh"))
+
+	if err := staging.Commit(); err == nil {
+		t.Fatal("Commit activated a half-written staging dir")
+	}
+	if got := readTestFile(t, filepath.Join(dir, "VERSION.txt")); got != "v1.0.0\n" {
+		t.Errorf("VERSION.txt = %q", got)
+	}
+	if _, err := os.Stat(staging.Dir); !os.IsNotExist(err) {
+		t.Error("staging dir was not removed")
+	}
+}
+
+func TestRecoverInterruptedSwap(t *testing.T) {
+	tests := []struct {
+		name        string
+		install     bool
+		staging     bool
+		wantRestore bool
+	}{
+		{name: "interrupted swap", staging: true, wantRestore: true},
+		{name: "install dir removed by the user", wantRestore: false},
+		{name: "install dir present", install: true, staging: true, wantRestore: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), ".devlauncher")
+			writeTestFile(t, filepath.Join(BackupDir(dir), "VERSION.txt"), "v1.0.0\n")
+			if tt.install {
+				writeTestFile(t, filepath.Join(dir, "VERSION.txt"), "v2.0.0\n")
+			}
+			if tt.staging {
+				writeTestFile(t, filepath.Join(StagingDir(dir), "VERSION.txt"), "v2.0.0\n")
+			}
+
+			if err := RecoverInterruptedSwap(dir); err != nil {
+				t.Fatal(err)
+			}
+			_, backupErr := os.Stat(BackupDir(dir))
+			if restored := os.IsNotExist(backupErr); restored != tt.wantRestore {
+				t.Errorf("restored = %v, want %v", restored, tt.wantRestore)
+			}
+			if tt.wantRestore {
+				data, err := os.ReadFile(filepath.Join(dir, "VERSION.txt"))
+				if err != nil || string(data) != "v1.0.0\n" {
+					t.Errorf("VERSION.txt = %q, %v", data, err)
+				}
+				if _, err := os.Stat(StagingDir(dir)); !os.IsNotExist(err) {
+					t.Error("staging dir was not removed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package installer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucas/installer/semver"
+)
+
+// Options are the choices of an installation, made in the TUI or with flags.
+type Options struct {
+	InstallDir string // Empty for GetInstallDir
+	Shell      bool   // Add DevScripts to the shell profile
+	Shortcut   bool   // Create the desktop shortcut
+	Launch     bool   // Start DevLauncher when done
+}
+
+// DefaultOptions returns what the installer does when nothing is changed.
+func DefaultOptions() Options {
+	return Options{Shell: true, Shortcut: true, Launch: true}
+}
+
+// ResolveInstallDir returns the installation directory of opts as an
+// absolute path, expanding a leading ~.
+func (o Options) ResolveInstallDir() (string, error) {
+	dir := strings.TrimSpace(o.InstallDir)
+	if dir == "" {
+		return GetInstallDir(), nil
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	return filepath.Abs(dir)
+}
+
+// Installation actions, from the embedded and installed versions.
+const (
+	ActionInstall   = "install"
+	ActionUpgrade   = "upgrade"
+	ActionReinstall = "reinstall"
+	ActionDowngrade = "downgrade"
+	ActionReplace   = "replace" // The installed VERSION.txt is not a valid version
+)
+
+// Step names and statuses of an unattended installation.
+const (
+	StepExtract     = "extract"
+	StepUninstaller = "uninstaller"
+	StepActivate    = "activate"
+	StepShell       = "shell"
+	StepShortcut    = "shortcut"
+
+	StepOK      = "ok"
+	StepSkipped = "skipped"
+	StepFailed  = "error"
+)
+
+// StepResult is the outcome of one installation step.
+type StepResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"` // Profile, shortcut path, file count...
+	Error  string `json:"error,omitempty"`
+}
+
+// Summary describes an unattended installation, printed at the end or as JSON.
+type Summary struct {
+	OK              bool         `json:"ok"`
+	InstallDir      string       `json:"install_dir"`
+	Version         string       `json:"version"`
+	PreviousVersion string       `json:"previous_version,omitempty"`
+	Action          string       `json:"action"`
+	Conflicts       []string     `json:"conflicts,omitempty"`   // Modified scripts shipped as <path>.new
+	Kept            []string     `json:"kept,omitempty"`        // Modified scripts left as they were
+	Overwritten     []string     `json:"overwritten,omitempty"` // Modified files outside scripts/
+	Removed         []string     `json:"removed,omitempty"`     // Files no longer shipped, deleted
+	Orphaned        []string     `json:"orphaned,omitempty"`    // Modified files no longer shipped, kept
+	Files           int          `json:"files"`
+	ShellProfile    string       `json:"shell_profile,omitempty"`
+	Shortcut        string       `json:"shortcut,omitempty"`
+	Backup          string       `json:"backup,omitempty"` // Previous installation, for --rollback or after a failed restore
+	Launcher        string       `json:"launcher"`
+	Steps           []StepResult `json:"steps"`
+	Warnings        []string     `json:"warnings,omitempty"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// Activated reports whether the new installation replaced the install dir.
+// Failures before that leave it as it was.
+func (s Summary) Activated() bool {
+	for _, step := range s.Steps {
+		if step.Name == StepActivate && step.Status == StepOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Untouched reports whether a failed installation left the install dir as
+// it was. It is false when Commit could not restore it (see Backup).
+func (s Summary) Untouched() bool {
+	return !s.Activated() && s.Backup == ""
+}
+
+// RunUnattended installs without the TUI, running the same steps in the same
+// order: extract the assets and generate the uninstaller in the staging
+// directory, activate it, configure the shell and create the desktop
+// shortcut. step is called after each one. It stops at the first failed
+// step; the summary tells which. Until activation the install dir is not
+// modified.
+func RunUnattended(fsys embed.FS, opts Options, step func(StepResult)) Summary {
+	s := Summary{Steps: []StepResult{}}
+	report := func(r StepResult) {
+		s.Steps = append(s.Steps, r)
+		if step != nil {
+			step(r)
+		}
+		if r.Status == StepFailed {
+			s.Error = fmt.Sprintf("%s: %s", r.Name, r.Error)
+		}
+	}
+
+	dir, err := opts.ResolveInstallDir()
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.InstallDir = dir
+	s.Launcher = GetLauncherPath(dir)
+	if data, err := fsys.ReadFile("assets/VERSION.txt"); err == nil {
+		s.Version = ParseVersion(string(data))
+	}
+	if _, err := semver.Parse(s.Version); err != nil {
+		s.Error = "VERSION.txt incrustado: " + err.Error()
+		return s
+	}
+
+	s.Action = ActionInstall
+	if err := RecoverInterruptedSwap(dir); err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	existing, err := DetectExistingInstall(dir)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	if existing != nil {
+		s.PreviousVersion = existing.Version
+		if existing.ManifestErr != nil {
+			s.Warnings = append(s.Warnings, ManifestWarning(existing.ManifestErr))
+		}
+		cmp, err := CompareVersions(s.Version, existing.Version)
+		switch {
+		case err != nil:
+			s.Action = ActionReplace
+		case cmp > 0:
+			s.Action = ActionUpgrade
+		case cmp == 0:
+			s.Action = ActionReinstall
+		default:
+			s.Action = ActionDowngrade
+		}
+	}
+
+	if existing != nil {
+		plan, err := PlanUpgrade(fsys, dir)
+		if err != nil {
+			s.Error = err.Error()
+			return s
+		}
+		s.Conflicts, s.Kept, s.Overwritten = plan.Conflicts, plan.Kept, plan.Overwritten
+		s.Removed, s.Orphaned = plan.Removed, plan.Orphaned
+	}
+
+	s.Files = CountAssets(fsys)
+	staging, err := BeginStaging(dir)
+	if err != nil {
+		report(StepResult{Name: StepExtract, Status: StepFailed, Error: err.Error()})
+		return s
+	}
+	if err := ExtractAssets(fsys, staging.Dir, nil); err != nil {
+		staging.Abort()
+		report(StepResult{Name: StepExtract, Status: StepFailed, Error: err.Error()})
+		return s
+	}
+	report(StepResult{Name: StepExtract, Status: StepOK, Detail: fmt.Sprintf("%d archivos", s.Files)})
+
+	if err := GenerateUninstaller(staging.Dir); err != nil {
+		staging.Abort()
+		report(StepResult{Name: StepUninstaller, Status: StepFailed, Error: err.Error()})
+		return s
+	}
+	report(StepResult{Name: StepUninstaller, Status: StepOK})
+
+	if err := staging.Commit(); err != nil {
+		if errors.Is(err, ErrRestoreFailed) {
+			s.Backup = BackupDir(dir)
+		}
+		report(StepResult{Name: StepActivate, Status: StepFailed, Error: err.Error()})
+		return s
+	}
+	detail := ""
+	if existing != nil {
+		s.Backup = BackupDir(dir)
+		detail = "copia anterior en " + s.Backup
+	}
+	report(StepResult{Name: StepActivate, Status: StepOK, Detail: detail})
+
+	if opts.Shell {
+		profile, err := ConfigureShell(dir)
+		if err != nil {
+			report(StepResult{Name: StepShell, Status: StepFailed, Error: err.Error()})
+			return s
+		}
+		s.ShellProfile = profile
+		report(StepResult{Name: StepShell, Status: StepOK, Detail: profile})
+	} else {
+		report(StepResult{Name: StepShell, Status: StepSkipped})
+	}
+
+	if opts.Shortcut {
+		path, err := CreateDesktopShortcut(dir)
+		if err != nil {
+			report(StepResult{Name: StepShortcut, Status: StepFailed, Error: err.Error()})
+			return s
+		}
+		s.Shortcut = path
+		report(StepResult{Name: StepShortcut, Status: StepOK, Detail: path})
+	} else {
+		report(StepResult{Name: StepShortcut, Status: StepSkipped})
+	}
+
+	s.OK = true
+	return s
+}
@@ -41,29 +41,64 @@ rm -f "$DESKTOP_DIR/DevLauncher.desktop" "$DESKTOP_DIR/DevLauncher" 2>/dev/null
 
 echo "Desinstalación en curso..."
 
-LEGACY_SCRIPTS_NAME=""
-if [[ -d "$INSTALL_DIR/scripts" ]]; then
-  SUFFIX="$(head /dev/urandom | tr -dc a-f0-9 | head -c 8)"
-  LEGACY_SCRIPTS_NAME="scripts-old-${SUFFIX}"
-  mv "$INSTALL_DIR/scripts" "$INSTALL_DIR/$LEGACY_SCRIPTS_NAME"
-fi
+# Previous installation kept for --rollback and leftover staging
+rm -rf "$INSTALL_DIR.backup" "$INSTALL_DIR.staging" 2>/dev/null || true
+
+MANIFEST="$INSTALL_DIR/manifest.tsv"
 
-for ITEM in "$INSTALL_DIR"/* "$INSTALL_DIR"/.[!.]* "$INSTALL_DIR"/..?*; do
-  [[ -e "$ITEM" ]] || continue
-  NAME="$(basename "$ITEM")"
-  if [[ -n "$LEGACY_SCRIPTS_NAME" && "$NAME" == "$LEGACY_SCRIPTS_NAME" ]]; then
-    continue
+sha256_of() {
+  if command -v sha256sum >/dev/null 2>&1; then
+    sha256sum "$1" | cut -d' ' -f1
+  else
+    shasum -a 256 "$1" | cut -d' ' -f1
   fi
-  rm -rf "$ITEM" 2>/dev/null || true
-done
+}
 
-if [[ -n "$LEGACY_SCRIPTS_NAME" ]]; then
-  echo "Scripts preservados en: $LEGACY_SCRIPTS_NAME"
+if [[ -f "$MANIFEST" ]]; then
+  # Only the files we installed and the user did not modify are removed
+  while IFS=$'\t' read -r SUM SIZE MODE VERSION FILE; do
+    [[ -z "$SUM" || "$SUM" == \#* ]] && continue
+    TARGET="$INSTALL_DIR/$FILE"
+    [[ -f "$TARGET" ]] || continue
+    if [[ "$(sha256_of "$TARGET")" == "$SUM" ]]; then
+      rm -f "$TARGET"
+    else
+      echo "Conservado (modificado): $FILE"
+    fi
+  done < "$MANIFEST"
+
+  rm -f "$MANIFEST" "$INSTALL_DIR/uninstaller.ps1" "$INSTALL_DIR/uninstaller.sh"
+  find "$INSTALL_DIR" -depth -type d -empty -delete 2>/dev/null || true
+
+  if [[ -d "$INSTALL_DIR" ]]; then
+    echo "Tus archivos modificados o añadidos siguen en: $INSTALL_DIR"
+  fi
+  echo "DevLauncher desinstalado."
 else
-  echo "No se encontró carpeta scripts para preservar."
-fi
+  LEGACY_SCRIPTS_NAME=""
+  if [[ -d "$INSTALL_DIR/scripts" ]]; then
+    SUFFIX="$(head /dev/urandom | tr -dc a-f0-9 | head -c 8)"
+    LEGACY_SCRIPTS_NAME="scripts-old-${SUFFIX}"
+    mv "$INSTALL_DIR/scripts" "$INSTALL_DIR/$LEGACY_SCRIPTS_NAME"
+  fi
+
+  for ITEM in "$INSTALL_DIR"/* "$INSTALL_DIR"/.[!.]* "$INSTALL_DIR"/..?*; do
+    [[ -e "$ITEM" ]] || continue
+    NAME="$(basename "$ITEM")"
+    if [[ -n "$LEGACY_SCRIPTS_NAME" && "$NAME" == "$LEGACY_SCRIPTS_NAME" ]]; then
+      continue
+    fi
+    rm -rf "$ITEM" 2>/dev/null || true
+  done
 
-echo "DevLauncher desinstalado (excepto scripts preservados)."
+  if [[ -n "$LEGACY_SCRIPTS_NAME" ]]; then
+    echo "Scripts preservados en: $LEGACY_SCRIPTS_NAME"
+  else
+    echo "No se encontró carpeta scripts para preservar."
+  fi
+
+  echo "DevLauncher desinstalado (excepto scripts preservados)."
+fi
 `
 
 	path := filepath.Join(installDir, "uninstaller.sh")
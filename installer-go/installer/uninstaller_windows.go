@@ -13,8 +13,11 @@ $ErrorActionPreference = "Stop"
 
 $installDir = Split-Path -Parent $MyInvocation.MyCommand.Path
 
+$manifestPath = Join-Path $installDir "manifest.tsv"
+$hasManifest = Test-Path $manifestPath
+
 $legacyScriptsName = ""
-if (Test-Path (Join-Path $installDir "scripts")) {
+if (-not $hasManifest -and (Test-Path (Join-Path $installDir "scripts"))) {
     $suffix = [Guid]::NewGuid().ToString("N").Substring(0, 8)
     $legacyScriptsName = "scripts-old-" + $suffix
     Rename-Item -Path (Join-Path $installDir "scripts") -NewName $legacyScriptsName -Force
@@ -62,23 +65,64 @@ try {
 
 Write-Host "Desinstalación en curso..."
 
-$itemsToRemove = Get-ChildItem -Path $installDir -Force -ErrorAction SilentlyContinue |
-    Where-Object { -not $legacyScriptsName -or $_.Name -ne $legacyScriptsName }
-
-foreach ($item in $itemsToRemove) {
-    try {
-        Remove-Item -Path $item.FullName -Recurse -Force -ErrorAction Stop
-    } catch {
-        # Ignore locked/current file errors (e.g. running uninstaller script)
+# Previous installation kept for --rollback and leftover staging
+foreach ($extra in @("$installDir.backup", "$installDir.staging")) {
+    if (Test-Path $extra) {
+        Remove-Item -Path $extra -Recurse -Force -ErrorAction SilentlyContinue
     }
 }
 
-if ($legacyScriptsName) {
-    Write-Host "Scripts preservados en: $legacyScriptsName"
+if ($hasManifest) {
+    # Only the files we installed and the user did not modify are removed
+    foreach ($line in Get-Content $manifestPath) {
+        if (-not $line -or $line.StartsWith("#")) { continue }
+        $fields = $line -split "\t", 5
+        if ($fields.Count -ne 5) { continue }
+        $target = Join-Path $installDir ($fields[4] -replace "/", "\")
+        if (-not (Test-Path $target -PathType Leaf)) { continue }
+        $hash = (Get-FileHash -Path $target -Algorithm SHA256).Hash.ToLower()
+        if ($hash -eq $fields[0]) {
+            try {
+                Remove-Item -Path $target -Force -ErrorAction Stop
+            } catch {
+                # Ignore locked/current file errors (e.g. running uninstaller)
+            }
+        } else {
+            Write-Host "Conservado (modificado): $($fields[4])"
+        }
+    }
+
+    foreach ($name in @("manifest.tsv", "uninstaller.sh", "uninstaller.ps1")) {
+        Remove-Item -Path (Join-Path $installDir $name) -Force -ErrorAction SilentlyContinue
+    }
+    Get-ChildItem -Path $installDir -Directory -Recurse -Force -ErrorAction SilentlyContinue |
+        Sort-Object { $_.FullName.Length } -Descending |
+        Where-Object { -not (Get-ChildItem -Path $_.FullName -Force) } |
+        Remove-Item -Force -ErrorAction SilentlyContinue
+
+    if (Get-ChildItem -Path $installDir -Force -ErrorAction SilentlyContinue) {
+        Write-Host "Tus archivos modificados o añadidos siguen en: $installDir"
+    }
+    Write-Host "DevLauncher desinstalado."
 } else {
-    Write-Host "No se encontró carpeta scripts para preservar."
+    $itemsToRemove = Get-ChildItem -Path $installDir -Force -ErrorAction SilentlyContinue |
+        Where-Object { -not $legacyScriptsName -or $_.Name -ne $legacyScriptsName }
+
+    foreach ($item in $itemsToRemove) {
+        try {
+            Remove-Item -Path $item.FullName -Recurse -Force -ErrorAction Stop
+        } catch {
+            # Ignore locked/current file errors (e.g. running uninstaller script)
+        }
+    }
+
+    if ($legacyScriptsName) {
+        Write-Host "Scripts preservados en: $legacyScriptsName"
+    } else {
+        Write-Host "No se encontró carpeta scripts para preservar."
+    }
+    Write-Host "DevLauncher desinstalado (excepto scripts preservados)."
 }
-Write-Host "DevLauncher desinstalado (excepto scripts preservados)."
 
 try {
     $completionCmd = "Write-Host 'Desinstalación completa de DevLauncher.' -ForegroundColor Green; Write-Host 'Vuelve pronto!' -ForegroundColor Yellow; Write-Host 'Puedes cerrar esta ventana.'"
@@ -0,0 +1,205 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NewSuffix is appended to the shipped copy of a script the user modified
+// when the new version changes it too.
+const NewSuffix = ".new"
+
+// upgradeAction is what ExtractAssets does with one embedded file, from the
+// previous manifest, the file on disk and the embedded copy.
+type upgradeAction int
+
+const (
+	actionAdd       upgradeAction = iota // Not on disk: write it
+	actionUnchanged                      // Disk already has the embedded copy
+	actionUpdate                         // Disk has what we installed: replace it
+	actionOverwrite                      // Modified outside scripts/: replace it
+	actionKeep                           // Modified script the new version does not change
+	actionConflict                       // Modified script changed by both: write <path>.new
+)
+
+// UpgradePlan summarises what installing over destDir does to its files.
+// Paths are slash-separated and relative to the install dir.
+type UpgradePlan struct {
+	Added       []string // New or deleted files that will be written
+	Updated     []string // Files unchanged since installed, replaced by the new version
+	Kept        []string // Modified scripts the new version does not touch
+	Conflicts   []string // Modified scripts the new version changes; shipped as <path>.new
+	Overwritten []string // Modified files outside scripts/, replaced
+	Removed     []string // Installed files the new version no longer ships, deleted
+	Orphaned    []string // Modified files the new version no longer ships, kept
+}
+
+// TouchesUserFiles reports whether any file modified by the user is kept,
+// in conflict or overwritten.
+func (p *UpgradePlan) TouchesUserFiles() bool {
+	return len(p.Kept) > 0 || len(p.Conflicts) > 0 || len(p.Overwritten) > 0 || len(p.Orphaned) > 0
+}
+
+func (p *UpgradePlan) record(rel string, action upgradeAction) {
+	switch action {
+	case actionAdd:
+		p.Added = append(p.Added, rel)
+	case actionUpdate:
+		p.Updated = append(p.Updated, rel)
+	case actionKeep:
+		p.Kept = append(p.Kept, rel)
+	case actionConflict:
+		p.Conflicts = append(p.Conflicts, rel)
+	case actionOverwrite:
+		p.Overwritten = append(p.Overwritten, rel)
+	}
+}
+
+// PlanUpgrade compares the embedded assets with the installation in destDir
+// without writing anything, for the installer to show before extracting.
+func PlanUpgrade(fsys fs.FS, destDir string) (*UpgradePlan, error) {
+	prev := previousEntries(destDir)
+	plan := &UpgradePlan{}
+	shipped := map[string]bool{}
+	err := walkAssets(fsys, destDir, func(path, destPath, rel string) error {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		action := planFile(destPath, rel, data, prev)
+		plan.record(rel, action)
+		shipped[rel] = true
+		if action == actionConflict {
+			shipped[rel+NewSuffix] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Removed, plan.Orphaned = retiredFiles(destDir, prev, shipped)
+	return plan, nil
+}
+
+// previousEntries indexes the manifest of destDir by path. It is nil when
+// there is no manifest; an unreadable manifest counts as none
+// (DetectExistingInstall reports it).
+func previousEntries(destDir string) map[string]ManifestEntry {
+	m, err := ReadManifest(destDir)
+	if err != nil || m == nil {
+		return nil
+	}
+	entries := make(map[string]ManifestEntry, len(m.Entries))
+	for _, e := range m.Entries {
+		entries[e.Path] = e
+	}
+	return entries
+}
+
+// planFile decides what to do with one embedded file (three-way comparison).
+func planFile(destPath, rel string, data []byte, prev map[string]ManifestEntry) upgradeAction {
+	if _, err := os.Stat(destPath); err != nil {
+		return actionAdd
+	}
+	sum := sha256.Sum256(data)
+	shipped := hex.EncodeToString(sum[:])
+	onDisk, err := fileSHA256(destPath)
+	if err != nil {
+		return actionOverwrite
+	}
+	if onDisk == shipped {
+		return actionUnchanged
+	}
+	if prev == nil {
+		// Nothing tells an edited script from an older release: keep it
+		if strings.HasPrefix(rel, "scripts/") {
+			return actionConflict
+		}
+		return actionUpdate
+	}
+	entry, installed := prev[rel]
+	if installed && onDisk == entry.SHA256 {
+		return actionUpdate
+	}
+	if !strings.HasPrefix(rel, "scripts/") {
+		return actionOverwrite
+	}
+	if installed && entry.SHA256 == shipped {
+		// The conflict is still open while the shipped .new copy is there
+		if _, pending := prev[rel+NewSuffix]; pending {
+			if _, err := os.Stat(destPath + NewSuffix); err == nil {
+				return actionConflict
+			}
+		}
+		return actionKeep
+	}
+	return actionConflict
+}
+
+// retiredFiles returns the files of the previous manifest that are not in
+// shipped, split into those still as installed and those the user modified.
+// Files already deleted are left out.
+func retiredFiles(destDir string, prev map[string]ManifestEntry, shipped map[string]bool) (unchanged, modified []string) {
+	for rel, e := range prev {
+		if shipped[rel] || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+		switch e.status(destDir) {
+		case fileUnchanged:
+			unchanged = append(unchanged, rel)
+		case fileModified:
+			modified = append(modified, rel)
+		}
+	}
+	sort.Strings(unchanged)
+	sort.Strings(modified)
+	return unchanged, modified
+}
+
+// removeRetired deletes the given files of destDir and the folders they
+// leave empty.
+func removeRetired(destDir string, rels []string) error {
+	for _, rel := range rels {
+		path := filepath.Join(destDir, filepath.FromSlash(rel))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for dir := filepath.Dir(path); dir != filepath.Clean(destDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// walkAssets calls fn for each embedded asset installed on this OS, with
+// its embedded path, destination path and manifest path.
+func walkAssets(fsys fs.FS, destDir string, fn func(path, destPath, rel string) error) error {
+	return fs.WalkDir(fsys, "assets", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		base := filepath.Base(path)
+		if base == ".gitkeep" || base == "placeholder" {
+			return nil
+		}
+		destPath := mapAssetPath(path, destDir)
+		if destPath == "" {
+			return nil
+		}
+		rel, err := filepath.Rel(destDir, destPath)
+		if err != nil {
+			return err
+		}
+		return fn(path, destPath, filepath.ToSlash(rel))
+	})
+}
@@ -0,0 +1,137 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanFile(t *testing.T) {
+	const (
+		v1   = "echo v1\n"
+		v2   = "echo v2\n"
+		mine = "echo mine\n"
+	)
+	tests := []struct {
+		name     string
+		rel      string
+		onDisk   string // "" = file deleted
+		previous string // content recorded in the previous manifest; "" = not in it
+		pending  bool   // a .new copy from an earlier conflict is still there
+		noPrev   bool   // installation without manifest
+		shipped  string
+		want     upgradeAction
+	}{
+		{name: "unchanged", rel: "scripts/a.sh", onDisk: v1, previous: v1, shipped: v1, want: actionUnchanged},
+		{name: "user-modified", rel: "scripts/a.sh", onDisk: mine, previous: v1, shipped: v1, want: actionKeep},
+		{name: "shipped-changed", rel: "scripts/a.sh", onDisk: v1, previous: v1, shipped: v2, want: actionUpdate},
+		{name: "both changed", rel: "scripts/a.sh", onDisk: mine, previous: v1, shipped: v2, want: actionConflict},
+		{name: "both changed to the same", rel: "scripts/a.sh", onDisk: v2, previous: v1, shipped: v2, want: actionUnchanged},
+		{name: "deleted by user", rel: "scripts/a.sh", previous: v1, shipped: v2, want: actionAdd},
+		{name: "new script", rel: "scripts/a.sh", shipped: v2, want: actionAdd},
+		{name: "user file with a shipped name", rel: "scripts/a.sh", onDisk: mine, shipped: v2, want: actionConflict},
+		{name: "no previous manifest", rel: "scripts/a.sh", onDisk: mine, noPrev: true, shipped: v2, want: actionConflict},
+		{name: "no previous manifest outside scripts", rel: "static/a.txt", onDisk: mine, noPrev: true, shipped: v2, want: actionUpdate},
+		{name: "modified outside scripts", rel: "static/a.txt", onDisk: mine, previous: v1, shipped: v2, want: actionOverwrite},
+		{name: "pending conflict", rel: "scripts/a.sh", onDisk: mine, previous: v2, pending: true, shipped: v2, want: actionConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			destPath := filepath.Join(dir, filepath.FromSlash(tt.rel))
+			if tt.onDisk != "" {
+				writeTestFile(t, destPath, tt.onDisk)
+			}
+
+			prev := map[string]ManifestEntry{}
+			if tt.previous != "" {
+				prev[tt.rel] = newManifestEntry(tt.rel, []byte(tt.previous), 0644, "v1.0.0")
+			}
+			if tt.pending {
+				prev[tt.rel+NewSuffix] = newManifestEntry(tt.rel+NewSuffix, []byte(tt.shipped), 0644, "v1.0.0")
+				writeTestFile(t, destPath+NewSuffix, tt.shipped)
+			}
+			if tt.noPrev {
+				prev = nil
+			}
+
+			if got := planFile(destPath, tt.rel, []byte(tt.shipped), prev); got != tt.want {
+				t.Errorf("planFile = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanFileResolvedConflict(t *testing.T) {
+	// Once the user deletes the .new copy, the edited script is simply kept
+	dir := t.TempDir()
+	rel := "scripts/a.sh"
+	destPath := filepath.Join(dir, rel)
+	writeTestFile(t, destPath, "echo mine\n")
+	prev := map[string]ManifestEntry{
+		rel:             newManifestEntry(rel, []byte("echo v2\n"), 0644, "v2.0.0"),
+		rel + NewSuffix: newManifestEntry(rel+NewSuffix, []byte("echo v2\n"), 0644, "v2.0.0"),
+	}
+	if _, err := os.Stat(destPath + NewSuffix); !os.IsNotExist(err) {
+		t.Fatal(".new should not exist")
+	}
+	if got := planFile(destPath, rel, []byte("echo v2\n"), prev); got != actionKeep {
+		t.Errorf("planFile = %d, want actionKeep", got)
+	}
+}
+
+func TestRetiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	prev := map[string]ManifestEntry{}
+	for rel, content := range map[string]string{
+		"scripts/old/gone.sh":      "echo gone\n",
+		"scripts/old/mine.sh":      "echo v1\n",
+		"scripts/kept.sh":          "echo kept\n",
+		"scripts/deleted.sh":       "echo deleted\n",
+		"scripts/a.sh" + NewSuffix: "echo v2\n",
+	} {
+		prev[rel] = newManifestEntry(rel, []byte(content), 0644, "v1.0.0")
+		if rel != "scripts/deleted.sh" {
+			writeTestFile(t, filepath.Join(dir, filepath.FromSlash(rel)), content)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, "scripts", "old", "mine.sh"), "echo mine\n")
+	prev["../outside.sh"] = newManifestEntry("../outside.sh", nil, 0644, "v1.0.0")
+
+	unchanged, modified := retiredFiles(dir, prev, map[string]bool{"scripts/kept.sh": true})
+	if want := []string{"scripts/a.sh" + NewSuffix, "scripts/old/gone.sh"}; !reflect.DeepEqual(unchanged, want) {
+		t.Errorf("unchanged = %v, want %v", unchanged, want)
+	}
+	if want := []string{"scripts/old/mine.sh"}; !reflect.DeepEqual(modified, want) {
+		t.Errorf("modified = %v, want %v", modified, want)
+	}
+
+	if err := removeRetired(dir, unchanged); err != nil {
+		t.Fatal(err)
+	}
+	for rel, exists := range map[string]bool{
+		"scripts/old/gone.sh":      false,
+		"scripts/a.sh" + NewSuffix: false,
+		"scripts/old/mine.sh":      true,
+		"scripts/kept.sh":          true,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); (err == nil) != exists {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, exists)
+		}
+	}
+}
+
+func TestRemoveRetiredPrunesEmptyFolders(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "scripts", "old", "deep", "a.sh"), "echo a\n")
+	if err := removeRetired(dir, []string{"scripts/old/deep/a.sh"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scripts")); !os.IsNotExist(err) {
+		t.Error("empty folders were left behind")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("the install dir itself was removed")
+	}
+}
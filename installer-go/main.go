@@ -10,7 +10,24 @@ import (
 )
 
 func main() {
-	m := tui.NewModel(assetsFS)
+	flags, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		fmt.Fprintln(os.Stderr, "Use --help to see available options")
+		os.Exit(2)
+	}
+	if flags.help {
+		printHelp()
+		return
+	}
+	if flags.rollback {
+		os.Exit(runRollback(flags))
+	}
+	if flags.yes {
+		os.Exit(runUnattended(flags))
+	}
+
+	m := tui.NewModel(assetsFS, flags.opts)
 	p := tea.NewProgram(&m, tea.WithAltScreen())
 	finalModel, err := p.Run()
 	if err != nil {
@@ -30,13 +47,40 @@ func main() {
 	}
 
 	if shouldLaunch {
-		cmd := exec.Command(launchPath)
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
+		if err := launch(launchPath); err != nil {
 			fmt.Fprintln(os.Stderr, "No se pudo iniciar DevLauncher:", err)
 			os.Exit(1)
 		}
 	}
 }
+
+// launch runs the installed launcher in this terminal
+func launch(path string) error {
+	cmd := exec.Command(path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func printHelp() {
+	fmt.Println("DevScripts Installer")
+	fmt.Println()
+	fmt.Println("Usage: installer [options]")
+	fmt.Println()
+	fmt.Println("Without --yes the interactive installer opens; --dir and the --no-* options")
+	fmt.Println("set its defaults.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -y, --yes         Install without asking (unattended, for provisioning scripts)")
+	fmt.Println("  --dir DIR         Installation directory (default ~/.devlauncher)")
+	fmt.Println("  --no-shell        Do not add devlauncher/dl/devscript to the shell profile")
+	fmt.Println("  --no-shortcut     Do not create the desktop shortcut")
+	fmt.Println("  --no-launch       Do not start DevLauncher when done")
+	fmt.Println("  -q, --quiet       Print only errors (requires --yes or --rollback, implies --no-launch)")
+	fmt.Println("  --json            Print the summary as JSON (requires --yes, implies --no-launch)")
+	fmt.Println("  --rollback        Restore the installation replaced by the last upgrade (with --dir)")
+	fmt.Println("  -h, --help        Show this help")
+	fmt.Println()
+	fmt.Println("Exit codes (--yes, --rollback): 0 done, 1 failed, 2 invalid options")
+}
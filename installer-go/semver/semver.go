@@ -0,0 +1,202 @@
+// Package semver parses and orders Semantic Versioning 2.0.0 versions
+// (https://semver.org), as written in VERSION.txt: an optional leading "v",
+// MAJOR.MINOR.PATCH, an optional -pre.release and optional +build metadata.
+// The launcher has the same package in launcher-go/semver; keep both in
+// sync.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major, Minor, Patch uint64
+	Prerelease          []string // Dot-separated identifiers after "-"
+	Build               []string // Dot-separated identifiers after "+"; ignored for precedence
+}
+
+// Parse reads a version such as "v1.4.0", "1.4.0-rc.1" or "v2.0.0+20260220".
+func Parse(s string) (Version, error) {
+	var v Version
+	text := strings.TrimSpace(s)
+	if strings.HasPrefix(text, "v") || strings.HasPrefix(text, "V") {
+		text = text[1:]
+	}
+	if text == "" {
+		return v, invalid(s, "está vacía")
+	}
+
+	text, build, hasBuild := strings.Cut(text, "+")
+	if hasBuild {
+		ids, err := identifiers(build, false)
+		if err != nil {
+			return v, invalid(s, "metadatos de compilación: "+err.Error())
+		}
+		v.Build = ids
+	}
+	core, pre, hasPre := strings.Cut(text, "-")
+	if hasPre {
+		ids, err := identifiers(pre, true)
+		if err != nil {
+			return v, invalid(s, "pre-release: "+err.Error())
+		}
+		v.Prerelease = ids
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, invalid(s, "se esperaba MAYOR.MENOR.PARCHE")
+	}
+	nums := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := number(part)
+		if err != nil {
+			return v, invalid(s, err.Error())
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+func invalid(s, reason string) error {
+	return fmt.Errorf("versión no válida %q: %s", s, reason)
+}
+
+// number parses a numeric identifier: digits only, without leading zeros.
+func number(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("número vacío")
+	}
+	if !isNumeric(s) {
+		return 0, fmt.Errorf("%q no es un número", s)
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q tiene ceros a la izquierda", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q es demasiado grande", s)
+	}
+	return n, nil
+}
+
+// identifiers splits a pre-release or build part. Identifiers are
+// [0-9A-Za-z-]; numeric pre-release ones cannot have leading zeros.
+func identifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("identificador vacío")
+		}
+		for _, r := range id {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return nil, fmt.Errorf("carácter %q no permitido en %q", r, id)
+			}
+		}
+		if prerelease && isNumeric(id) {
+			if _, err := number(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String returns the canonical form with a leading "v", like VERSION.txt.
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// IsPrerelease reports whether v has a pre-release part (e.g. -rc.1).
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 as v precedes, equals or follows o. Build
+// metadata is ignored, so v1.0.0+a and v1.0.0+b are equal.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A pre-release precedes its release: 1.0.0-rc.1 < 1.0.0
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+// compareIdentifier orders pre-release identifiers: numeric ones by value
+// and before alphanumeric ones, which compare in ASCII order.
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		x, _ := strconv.ParseUint(a, 10, 64)
+		y, _ := strconv.ParseUint(b, 10, 64)
+		return compareUint(x, y)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compare parses a and b and compares them (see Version.Compare).
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
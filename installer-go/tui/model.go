@@ -2,6 +2,7 @@ package tui
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -12,6 +13,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lucas/installer/installer"
+	"github.com/lucas/installer/semver"
 )
 
 // Phase represents the current installer phase.
@@ -39,6 +41,7 @@ type Model struct {
 	// detection
 	installDir  string
 	existing    *installer.ExistingInstall
+	plan        *installer.UpgradePlan // nil for a new installation
 	embeddedVer string
 
 	// install state
@@ -47,11 +50,15 @@ type Model struct {
 	currentFile  string
 	shellProfile string
 	shortcutPath string
+	backupDir    string // Previous installation kept for --rollback
+	untouched    bool   // The install failed before replacing the install dir
 	err          error
 
 	createShortcut  bool
+	configureShell  bool
 	launchAfterDone bool
 	launchPath      string
+	opts            installer.Options
 
 	// embed fs (passed from main)
 	assets embed.FS
@@ -68,8 +75,10 @@ type extractTask struct {
 
 // Messages
 type detectionDoneMsg struct {
+	err         error
 	installDir  string
 	existing    *installer.ExistingInstall
+	plan        *installer.UpgradePlan
 	embeddedVer string
 	totalFiles  int
 }
@@ -92,8 +101,9 @@ type shortcutDoneMsg struct {
 	err  error
 }
 
-// NewModel creates a new installer Model.
-func NewModel(assets embed.FS) Model {
+// NewModel creates a new installer Model. opts holds the defaults set with
+// command line flags; the shortcut can still be toggled in the TUI.
+func NewModel(assets embed.FS, opts installer.Options) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(ColorPurple))
@@ -105,7 +115,9 @@ func NewModel(assets embed.FS) Model {
 		spinner:        s,
 		progress:       p,
 		assets:         assets,
-		createShortcut: true,
+		createShortcut: opts.Shortcut,
+		configureShell: opts.Shell,
+		opts:           opts,
 	}
 }
 
@@ -138,8 +150,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case detectionDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.phase = PhaseError
+			return m, nil
+		}
 		m.installDir = msg.installDir
 		m.existing = msg.existing
+		m.plan = msg.plan
 		m.embeddedVer = msg.embeddedVer
 		m.totalFiles = msg.totalFiles
 		m.phase = PhaseConfirm
@@ -161,13 +179,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case extractDoneMsg:
 		if msg.err != nil {
 			m.err = msg.err
+			m.untouched = !errors.Is(msg.err, installer.ErrRestoreFailed)
 			m.phase = PhaseError
 			return m, nil
 		}
-		if err := installer.GenerateUninstaller(m.installDir); err != nil {
-			m.err = err
-			m.phase = PhaseError
-			return m, nil
+		if m.existing != nil {
+			m.backupDir = installer.BackupDir(m.installDir)
+		}
+		if !m.configureShell {
+			return m.Update(shellDoneMsg{})
 		}
 		m.phase = PhaseShellConfig
 		return m, tea.Batch(m.spinner.Tick, doShellConfig(m.installDir))
@@ -207,7 +227,7 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case PhaseSplash:
 		if msg.Type == tea.KeyEnter {
 			m.phase = PhaseDetecting
-			return m, tea.Batch(m.spinner.Tick, doDetection(m.assets))
+			return m, tea.Batch(m.spinner.Tick, doDetection(m.assets, m.opts))
 		}
 		if msg.Type == tea.KeyCtrlC {
 			return m, tea.Quit
@@ -247,22 +267,42 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // doDetection runs detection in background and returns detectionDoneMsg.
-func doDetection(assets embed.FS) tea.Cmd {
+func doDetection(assets embed.FS, opts installer.Options) tea.Cmd {
 	return func() tea.Msg {
-		installDir := installer.GetInstallDir()
-		existing, _ := installer.DetectExistingInstall(installDir)
+		installDir, err := opts.ResolveInstallDir()
+		if err != nil {
+			return detectionDoneMsg{err: err}
+		}
+		if err := installer.RecoverInterruptedSwap(installDir); err != nil {
+			return detectionDoneMsg{err: err}
+		}
+		existing, err := installer.DetectExistingInstall(installDir)
+		if err != nil {
+			return detectionDoneMsg{err: err}
+		}
+		var plan *installer.UpgradePlan
+		if existing != nil {
+			plan, err = installer.PlanUpgrade(assets, installDir)
+			if err != nil {
+				return detectionDoneMsg{err: err}
+			}
+		}
 
 		// Read embedded VERSION.txt
 		embeddedVer := ""
 		if data, err := assets.ReadFile("assets/VERSION.txt"); err == nil {
 			embeddedVer = installer.ParseVersion(string(data))
 		}
+		if _, err := semver.Parse(embeddedVer); err != nil {
+			return detectionDoneMsg{err: fmt.Errorf("VERSION.txt incrustado: %w", err)}
+		}
 
 		totalFiles := installer.CountAssets(assets)
 
 		return detectionDoneMsg{
 			installDir:  installDir,
 			existing:    existing,
+			plan:        plan,
 			embeddedVer: embeddedVer,
 			totalFiles:  totalFiles,
 		}
@@ -294,11 +334,23 @@ func (m *Model) startExtraction() tea.Cmd {
 }
 
 func (m *Model) doFullExtraction() tea.Msg {
-	// Extract all files and send first fileExtractedMsg
-	err := installer.ExtractAssets(m.assets, m.installDir, nil)
+	// Extract all files and the uninstaller into the staging directory,
+	// swap it with the install dir and send first fileExtractedMsg
+	staging, err := installer.BeginStaging(m.installDir)
 	if err != nil {
 		return extractDoneMsg{err}
 	}
+	if err := installer.ExtractAssets(m.assets, staging.Dir, nil); err != nil {
+		staging.Abort()
+		return extractDoneMsg{err}
+	}
+	if err := installer.GenerateUninstaller(staging.Dir); err != nil {
+		staging.Abort()
+		return extractDoneMsg{err}
+	}
+	if err := staging.Commit(); err != nil {
+		return extractDoneMsg{err}
+	}
 	total := m.totalFiles
 	if total == 0 {
 		total = installer.CountAssets(m.assets)
@@ -327,6 +379,9 @@ func doDesktopShortcut(installDir string) tea.Cmd {
 }
 
 func (m *Model) prepareLaunch() {
+	if !m.opts.Launch {
+		return
+	}
 	launcherPath := installer.GetLauncherPath(m.installDir)
 	if _, err := os.Stat(launcherPath); err == nil {
 		m.launchAfterDone = true
@@ -386,8 +441,12 @@ func (m Model) viewConfirm() string {
 			sb.WriteString(CyanStyle.Render("Versión:    "+m.embeddedVer) + "\n")
 		}
 	} else {
-		cmp := installer.CompareVersions(m.embeddedVer, m.existing.Version)
-		if cmp == 0 {
+		cmp, err := installer.CompareVersions(m.embeddedVer, m.existing.Version)
+		if err != nil {
+			sb.WriteString(TitleStyle.Render(fmt.Sprintf("⚠ Versión instalada no reconocida: %q", m.existing.Version)) + "\n")
+			sb.WriteString(NormalStyle.Render("Directorio: "+m.installDir) + "\n")
+			sb.WriteString(CyanStyle.Render("Se reemplazará por "+m.embeddedVer) + "\n")
+		} else if cmp == 0 {
 			sb.WriteString(SuccessStyle.Render("✓ Ya tienes la última versión") + "\n")
 			sb.WriteString(NormalStyle.Render("Directorio: "+m.installDir) + "\n")
 			sb.WriteString(DimStyle.Render("Versión instalada: "+m.existing.Version) + "\n")
@@ -398,6 +457,18 @@ func (m Model) viewConfirm() string {
 			sb.WriteString(TitleStyle.Render(fmt.Sprintf("⚠ Versión incrustada %s < instalada %s", m.embeddedVer, m.existing.Version)) + "\n")
 			sb.WriteString(NormalStyle.Render("Directorio: "+m.installDir) + "\n")
 		}
+		if m.existing.ManifestErr != nil {
+			sb.WriteString(TitleStyle.Render("⚠ "+installer.ManifestWarning(m.existing.ManifestErr)) + "\n")
+		}
+		if n := len(m.existing.Missing); n > 0 {
+			sb.WriteString(DimStyle.Render(fmt.Sprintf("  %d archivo(s) borrados desde la instalación se restaurarán", n)) + "\n")
+		}
+		if m.plan != nil && len(m.plan.Removed) > 0 {
+			sb.WriteString(DimStyle.Render(fmt.Sprintf("  %d archivo(s) que esta versión ya no incluye se borrarán", len(m.plan.Removed))) + "\n")
+		}
+		if m.plan != nil && m.plan.TouchesUserFiles() {
+			sb.WriteString("\n" + m.viewConflicts())
+		}
 	}
 
 	sb.WriteString("\n")
@@ -407,11 +478,47 @@ func (m Model) viewConfirm() string {
 	} else {
 		sb.WriteString(DimStyle.Render("Acceso directo escritorio: desactivado  (pulsa d para activar)") + "\n\n")
 	}
+	if !m.configureShell {
+		sb.WriteString(DimStyle.Render("Perfil de shell: no se modificará (--no-shell)") + "\n\n")
+	}
 	sb.WriteString(SuccessStyle.Render("[y] Instalar") + "  " + ErrorStyle.Render("[q] Cancelar"))
 
 	return m.center(BoxStyle.Render(sb.String()))
 }
 
+// viewConflicts summarises what the upgrade does with files the user modified.
+func (m Model) viewConflicts() string {
+	var sb strings.Builder
+	if n := len(m.plan.Conflicts); n > 0 {
+		sb.WriteString(TitleStyle.Render(fmt.Sprintf("⚠ %d script(s) que modificaste cambian en esta versión:", n)) + "\n")
+		for i, path := range m.plan.Conflicts {
+			if i == 5 {
+				sb.WriteString(DimStyle.Render(fmt.Sprintf("  … y %d más", n-5)) + "\n")
+				break
+			}
+			sb.WriteString(NormalStyle.Render("  "+path) + DimStyle.Render(" → "+path+installer.NewSuffix) + "\n")
+		}
+		sb.WriteString(DimStyle.Render("  Se conservan tus cambios; la versión nueva se guarda como "+installer.NewSuffix) + "\n")
+	}
+	if n := len(m.plan.Kept); n > 0 {
+		sb.WriteString(SuccessStyle.Render(fmt.Sprintf("✓ %d script(s) modificados se conservan (no cambian en esta versión)", n)) + "\n")
+	}
+	if n := len(m.plan.Overwritten); n > 0 {
+		sb.WriteString(ErrorStyle.Render(fmt.Sprintf("⚠ %d archivo(s) modificados fuera de scripts/ se sobrescribirán", n)) + "\n")
+	}
+	if n := len(m.plan.Orphaned); n > 0 {
+		sb.WriteString(TitleStyle.Render(fmt.Sprintf("⚠ %d archivo(s) que modificaste ya no forman parte de esta versión; se conservan:", n)) + "\n")
+		for i, path := range m.plan.Orphaned {
+			if i == 5 {
+				sb.WriteString(DimStyle.Render(fmt.Sprintf("  … y %d más", n-5)) + "\n")
+				break
+			}
+			sb.WriteString(NormalStyle.Render("  "+path) + "\n")
+		}
+	}
+	return sb.String()
+}
+
 func (m Model) viewInstalling() string {
 	var sb strings.Builder
 	sb.WriteString(TitleStyle.Render("Instalando archivos...") + "\n\n")
@@ -454,12 +561,15 @@ func (m Model) viewDone() string {
 	if m.shortcutPath != "" {
 		sb.WriteString(NormalStyle.Render("Acceso directo: "+m.shortcutPath) + "\n")
 	}
+	if m.backupDir != "" {
+		sb.WriteString(DimStyle.Render("Copia anterior: "+m.backupDir+"  (installer --rollback para restaurarla)") + "\n")
+	}
 	sb.WriteString("\n")
 	sb.WriteString(CyanStyle.Render("Para activar, ejecuta:") + "\n")
 	sb.WriteString(PurpleStyle.Render("  "+sourceCmd) + "\n\n")
 	sb.WriteString(TitleStyle.Render("Comandos disponibles") + "\n")
 	sb.WriteString(TitleStyle.Render("  • devlauncher") + DimStyle.Render(" (alias: dl)") + "\n")
-	sb.WriteString(TitleStyle.Render("  • devscript <nombre_script>") + DimStyle.Render(" (ejecución directa)") + "\n\n")
+	sb.WriteString(TitleStyle.Render("  • devscript <categoria/script>") + DimStyle.Render(" (ejecución directa, = devlauncher run)") + "\n\n")
 	if m.launchAfterDone {
 		sb.WriteString(CyanStyle.Render("Pulsa Enter para continuar") + "\n")
 		sb.WriteString(DimStyle.Render("Al continuar, se iniciará DevLauncher automáticamente."))
@@ -476,8 +586,11 @@ func (m Model) viewError() string {
 		msg = m.err.Error()
 	}
 	inner := ErrorStyle.Render("✗ Error durante la instalación") + "\n\n" +
-		NormalStyle.Render(msg) + "\n\n" +
-		DimStyle.Render("Presiona cualquier tecla para salir")
+		NormalStyle.Render(msg) + "\n\n"
+	if m.untouched {
+		inner += SuccessStyle.Render("La instalación en "+m.installDir+" no se ha modificado") + "\n\n"
+	}
+	inner += DimStyle.Render("Presiona cualquier tecla para salir")
 	return m.center(BoxStyle.Render(inner))
 }
 
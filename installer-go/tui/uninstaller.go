@@ -35,9 +35,11 @@ type UninstallModel struct {
 	height      int
 	installDir  string
 	existing    *installer.ExistingInstall
-	removeShell bool   // whether to also clean shell config
-	shellCursor int    // 0 = Yes, 1 = No (for shell removal prompt)
-	shellFile   string // path modified
+	removeShell bool     // whether to also clean shell config
+	shellCursor int      // 0 = Yes, 1 = No (for shell removal prompt)
+	shellFile   string   // path modified
+	removed     int      // Files removed using the manifest
+	kept        []string // Files modified or added by the user, left in place
 	err         error
 }
 
@@ -45,8 +47,13 @@ type UninstallModel struct {
 type uninstallDetectionDoneMsg struct {
 	installDir string
 	existing   *installer.ExistingInstall
+	err        error
+}
+type uninstallRemovedMsg struct {
+	removed int
+	kept    []string
+	err     error
 }
-type uninstallRemovedMsg struct{ err error }
 type uninstallShellDoneMsg struct {
 	file string
 	err  error
@@ -96,6 +103,11 @@ func (m UninstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case uninstallDetectionDoneMsg:
 		m.installDir = msg.installDir
 		m.existing = msg.existing
+		if msg.err != nil {
+			m.err = msg.err
+			m.phase = UninstallPhaseError
+			return m, nil
+		}
 		if msg.existing == nil {
 			m.phase = UninstallPhaseNotFound
 		} else {
@@ -109,6 +121,7 @@ func (m UninstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.phase = UninstallPhaseError
 			return m, nil
 		}
+		m.removed, m.kept = msg.removed, msg.kept
 		if m.removeShell {
 			m.phase = UninstallPhaseShell
 			return m, tea.Batch(m.spinner.Tick, doRemoveShell())
@@ -156,7 +169,7 @@ func (m UninstallModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.removeShell = m.shellCursor == 0
 			m.phase = UninstallPhaseRemoving
 			cmd := m.progress.SetPercent(0)
-			return m, tea.Batch(cmd, doRemoveDir(m.installDir))
+			return m, tea.Batch(cmd, doRemoveDir(m.installDir, m.existing))
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		}
@@ -173,15 +186,24 @@ func (m UninstallModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func doUninstallDetection() tea.Cmd {
 	return func() tea.Msg {
 		dir := installer.GetInstallDir()
-		existing, _ := installer.DetectExistingInstall(dir)
-		return uninstallDetectionDoneMsg{installDir: dir, existing: existing}
+		existing, err := installer.DetectExistingInstall(dir)
+		return uninstallDetectionDoneMsg{installDir: dir, existing: existing, err: err}
 	}
 }
 
-func doRemoveDir(installDir string) tea.Cmd {
+// doRemoveDir removes only the files listed in the manifest that were not
+// modified; installations without a manifest are removed entirely. The
+// backup of the previous installation goes too.
+func doRemoveDir(installDir string, existing *installer.ExistingInstall) tea.Cmd {
 	return func() tea.Msg {
-		err := installer.RemoveInstallDir(installDir)
-		return uninstallRemovedMsg{err: err}
+		if err := installer.RemoveBackup(installDir); err != nil {
+			return uninstallRemovedMsg{err: err}
+		}
+		if existing == nil || existing.Manifest == nil {
+			return uninstallRemovedMsg{err: installer.RemoveInstallDir(installDir)}
+		}
+		removed, kept, err := installer.RemoveOwnedFiles(installDir, existing.Manifest)
+		return uninstallRemovedMsg{removed: removed, kept: kept, err: err}
 	}
 }
 
@@ -230,10 +252,21 @@ func (m UninstallModel) viewUDetecting() string {
 
 func (m UninstallModel) viewUConfirm() string {
 	var sb strings.Builder
+	if m.existing != nil && m.existing.ManifestErr != nil {
+		sb.WriteString(TitleStyle.Render("⚠ "+installer.ManifestWarning(m.existing.ManifestErr)) + "\n\n")
+	}
 	sb.WriteString(ErrorStyle.Render("⚠  Se eliminará:") + "\n")
-	sb.WriteString(NormalStyle.Render("  Directorio: "+m.installDir) + "\n")
-	sb.WriteString(TitleStyle.Render("  Se conserva: scripts/") + DimStyle.Render(" (se renombra a scripts-old-<random>)") + "\n")
-	sb.WriteString(SuccessStyle.Render("  Tus scripts NO se perderán") + "\n")
+	if m.existing != nil && m.existing.Manifest != nil {
+		sb.WriteString(NormalStyle.Render(fmt.Sprintf("  Los %d archivos instalados en %s", len(m.existing.Manifest.Entries), m.installDir)) + "\n")
+		if n := len(m.existing.Modified); n > 0 {
+			sb.WriteString(TitleStyle.Render(fmt.Sprintf("  Se conservan %d archivo(s) que modificaste", n)) + "\n")
+		}
+		sb.WriteString(SuccessStyle.Render("  Los archivos que añadiste NO se tocan") + "\n")
+	} else {
+		sb.WriteString(NormalStyle.Render("  Directorio: "+m.installDir) + "\n")
+		sb.WriteString(TitleStyle.Render("  Se conserva: scripts/") + DimStyle.Render(" (se renombra a scripts-old-<random>)") + "\n")
+		sb.WriteString(SuccessStyle.Render("  Tus scripts NO se perderán") + "\n")
+	}
 	if m.existing != nil && m.existing.Version != "" {
 		sb.WriteString(DimStyle.Render("  Versión:     "+m.existing.Version) + "\n")
 	}
@@ -278,8 +311,22 @@ func (m UninstallModel) viewUDone() string {
 
 	var sb strings.Builder
 	sb.WriteString(SuccessStyle.Render("✓ Desinstalación completada") + "\n\n")
-	sb.WriteString(NormalStyle.Render("Eliminado: contenido de "+m.installDir) + "\n")
-	sb.WriteString(TitleStyle.Render("Conservado: scripts-old-<random> (si existía scripts/)") + "\n")
+	if m.existing != nil && m.existing.Manifest != nil {
+		sb.WriteString(NormalStyle.Render(fmt.Sprintf("Eliminados: %d archivos de %s", m.removed, m.installDir)) + "\n")
+		if len(m.kept) > 0 {
+			sb.WriteString(TitleStyle.Render(fmt.Sprintf("Conservados: %d archivo(s) modificados o añadidos por ti", len(m.kept))) + "\n")
+			for i, path := range m.kept {
+				if i == 5 {
+					sb.WriteString(DimStyle.Render(fmt.Sprintf("  … y %d más", len(m.kept)-5)) + "\n")
+					break
+				}
+				sb.WriteString(DimStyle.Render("  "+path) + "\n")
+			}
+		}
+	} else {
+		sb.WriteString(NormalStyle.Render("Eliminado: contenido de "+m.installDir) + "\n")
+		sb.WriteString(TitleStyle.Render("Conservado: scripts-old-<random> (si existía scripts/)") + "\n")
+	}
 	if m.removeShell && m.shellFile != "" {
 		sb.WriteString(NormalStyle.Render("Perfiles:  "+m.shellFile) + "\n")
 		sb.WriteString("\n" + CyanStyle.Render("Para aplicar los cambios:") + "\n")
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/lucas/installer/installer"
+	"github.com/lucas/installer/tui"
+)
+
+type installerFlags struct {
+	opts     installer.Options
+	yes      bool
+	rollback bool
+	quiet    bool
+	json     bool
+	help     bool
+}
+
+// parseFlags reads the command line options. They are parsed by hand, like
+// the launcher does, so both -x and --x forms work and --dir=DIR is accepted.
+func parseFlags(args []string) (installerFlags, error) {
+	f := installerFlags{opts: installer.DefaultOptions()}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-y", "--yes":
+			f.yes = true
+		case "--rollback":
+			f.rollback = true
+		case "--dir":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return f, fmt.Errorf("--dir requires a directory")
+				}
+				i++
+				value = args[i]
+			}
+			if strings.TrimSpace(value) == "" {
+				return f, fmt.Errorf("--dir requires a directory")
+			}
+			f.opts.InstallDir = value
+		case "--no-shell":
+			f.opts.Shell = false
+		case "--no-shortcut":
+			f.opts.Shortcut = false
+		case "--no-launch":
+			f.opts.Launch = false
+		case "-q", "--quiet":
+			f.quiet = true
+		case "--json":
+			f.json = true
+		case "-h", "--help":
+			f.help = true
+		default:
+			return f, fmt.Errorf("unknown option: %s", arg)
+		}
+		if hasValue && name != "--dir" {
+			return f, fmt.Errorf("%s does not take a value", name)
+		}
+	}
+	if f.rollback && (f.yes || f.json) {
+		return f, fmt.Errorf("--rollback cannot be combined with --yes or --json")
+	}
+	if f.json && !f.yes {
+		return f, fmt.Errorf("--json requires --yes")
+	}
+	if f.quiet && !f.yes && !f.rollback {
+		return f, fmt.Errorf("--quiet requires --yes or --rollback")
+	}
+	if f.quiet || f.json {
+		f.opts.Launch = false
+	}
+	return f, nil
+}
+
+// runUnattended installs without the TUI and returns the exit code
+func runUnattended(f installerFlags) int {
+	var progress func(installer.StepResult)
+	if !f.quiet && !f.json {
+		if dir, err := f.opts.ResolveInstallDir(); err == nil {
+			fmt.Println(tui.TitleStyle.Render("🚀 DevScripts Installer") + " → " + dir)
+		}
+		progress = printStep
+	}
+
+	summary := installer.RunUnattended(assetsFS, f.opts, progress)
+	if !f.json {
+		for _, warning := range summary.Warnings {
+			fmt.Fprintln(os.Stderr, tui.TitleStyle.Render("⚠ "+warning))
+		}
+	}
+
+	switch {
+	case f.json:
+		data, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(data))
+	case !summary.OK:
+		fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render("✗ Error durante la instalación: "+summary.Error))
+		if summary.Untouched() {
+			fmt.Fprintln(os.Stderr, "La instalación en "+summary.InstallDir+" no se ha modificado.")
+		}
+	case !f.quiet:
+		printSummary(summary)
+	}
+	if !summary.OK {
+		return 1
+	}
+
+	if f.opts.Launch {
+		if _, err := os.Stat(summary.Launcher); err == nil {
+			if err := launch(summary.Launcher); err != nil {
+				fmt.Fprintln(os.Stderr, "No se pudo iniciar DevLauncher:", err)
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+var stepLabels = map[string]string{
+	installer.StepExtract:     "Archivos",
+	installer.StepUninstaller: "Desinstalador",
+	installer.StepActivate:    "Activación",
+	installer.StepShell:       "Perfil de shell",
+	installer.StepShortcut:    "Acceso directo",
+}
+
+func printStep(r installer.StepResult) {
+	label := stepLabels[r.Name]
+	switch r.Status {
+	case installer.StepOK:
+		line := "✓ " + label
+		if r.Detail != "" {
+			line += ": " + r.Detail
+		}
+		fmt.Println(tui.SuccessStyle.Render(line))
+	case installer.StepSkipped:
+		fmt.Println(tui.DimStyle.Render("- " + label + ": omitido"))
+	case installer.StepFailed:
+		fmt.Println(tui.ErrorStyle.Render("✗ " + label + ": " + r.Error))
+	}
+}
+
+func printSummary(s installer.Summary) {
+	switch s.Action {
+	case installer.ActionUpgrade:
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✨ Actualizado %s → %s", s.PreviousVersion, s.Version)))
+	case installer.ActionReinstall:
+		fmt.Println(tui.SuccessStyle.Render("✨ Reinstalado " + s.Version))
+	case installer.ActionDowngrade:
+		fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("⚠ Instalada %s sobre una versión más nueva (%s)", s.Version, s.PreviousVersion)))
+	case installer.ActionReplace:
+		fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("✨ Instalada %s (la versión anterior %q no era válida)", s.Version, s.PreviousVersion)))
+	default:
+		fmt.Println(tui.SuccessStyle.Render("✨ ¡Instalación completada! " + s.Version))
+	}
+	if n := len(s.Conflicts); n > 0 {
+		fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("⚠ %d script(s) que modificaste cambian en esta versión; la nueva está en %s:", n, installer.NewSuffix)))
+		for _, path := range s.Conflicts {
+			fmt.Println(tui.DimStyle.Render("  " + path + installer.NewSuffix))
+		}
+	}
+	if n := len(s.Kept); n > 0 {
+		fmt.Println(tui.DimStyle.Render(fmt.Sprintf("Se conservaron %d script(s) modificados", n)))
+	}
+	if n := len(s.Overwritten); n > 0 {
+		fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("⚠ Se sobrescribieron %d archivo(s) modificados fuera de scripts/", n)))
+	}
+	if n := len(s.Removed); n > 0 {
+		fmt.Println(tui.DimStyle.Render(fmt.Sprintf("Se borraron %d archivo(s) que esta versión ya no incluye", n)))
+	}
+	if n := len(s.Orphaned); n > 0 {
+		fmt.Println(tui.TitleStyle.Render(fmt.Sprintf("⚠ %d archivo(s) que modificaste ya no forman parte de esta versión; se conservan:", n)))
+		for _, path := range s.Orphaned {
+			fmt.Println(tui.DimStyle.Render("  " + path))
+		}
+	}
+	if s.Backup != "" {
+		fmt.Println(tui.DimStyle.Render("Para volver a " + s.PreviousVersion + ": installer --rollback"))
+	}
+	if s.ShellProfile != "" {
+		sourceCmd := "source " + s.ShellProfile
+		if runtime.GOOS == "windows" {
+			sourceCmd = ". $PROFILE"
+		}
+		fmt.Println(tui.CyanStyle.Render("Para activar, ejecuta: ") + tui.PurpleStyle.Render(sourceCmd))
+	}
+}
+
+// runRollback restores the installation kept by the last upgrade and
+// returns the exit code
+func runRollback(f installerFlags) int {
+	dir, err := f.opts.ResolveInstallDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render("✗ "+err.Error()))
+		return 1
+	}
+	version, err := installer.Rollback(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render("✗ No se pudo restaurar: "+err.Error()))
+		return 1
+	}
+	if !f.quiet {
+		fmt.Println(tui.SuccessStyle.Render(fmt.Sprintf("✓ Restaurada la instalación anterior %s en %s", version, dir)))
+	}
+	return 0
+}
@@ -0,0 +1,527 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucas/launcher/interpreter"
+	"gopkg.in/yaml.v3"
+)
+
+// Header values besides a file name inside static/
+const (
+	HeaderRandom = "random"
+	HeaderNone   = "none"
+)
+
+// Run modes: the script takes over the terminal, or runs captured in ExecutingView.
+// RunModeBackground starts it as a job and is only valid in the @mode header.
+const (
+	RunModeTerminal   = "terminal"
+	RunModeCapture    = "capture"
+	RunModeBackground = "background"
+)
+
+// Config is the launcher configuration file. Zero values are never used
+// directly: Load starts from Default and only overrides the keys present.
+type Config struct {
+	HiddenFolders  []string `yaml:"hidden_folders"`
+	IgnorePrefixes []string `yaml:"ignore_prefixes"`
+	Extensions     []string `yaml:"extensions"`
+	OutputWidth    int      `yaml:"output_width"`
+	Header         string   `yaml:"header"`
+	ScriptDirs     []string `yaml:"script_dirs"`
+	Timeout        Duration `yaml:"timeout"`
+	KillGrace      Duration `yaml:"kill_grace"`
+	RunMode        string   `yaml:"run_mode"`
+	// Scripts that need a typed confirmation: names or category/path globs
+	DangerousScripts []string `yaml:"dangerous_scripts"`
+	// List executable files with a #! line whatever their extension (not on Windows)
+	DetectExecutables bool `yaml:"detect_executables"`
+
+	// Interpreters added to (or replacing) the built-in ones. Edited in the
+	// file only, it is not a "launcher config set" key.
+	Interpreters []interpreter.Interpreter `yaml:"interpreters,omitempty"`
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	extensions := []string{".sh"}
+	if runtime.GOOS == "windows" {
+		extensions = []string{".ps1", ".bat"}
+	}
+	return Config{
+		HiddenFolders:  []string{"lib"},
+		IgnorePrefixes: []string{"example_"},
+		Extensions:     extensions,
+		OutputWidth:    0,
+		Header:         HeaderRandom,
+		KillGrace:      Duration(5 * time.Second),
+		RunMode:        RunModeTerminal,
+
+		DetectExecutables: true,
+	}
+}
+
+// Duration is a time.Duration written in the file as "90s", "5m" or plain
+// seconds (see ParseDuration)
+type Duration time.Duration
+
+// String formats d like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// UnmarshalYAML decodes a duration with ParseDuration
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("se esperaba una duración")
+	}
+	parsed, err := ParseDuration(value.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalYAML writes the duration as text, so Load reads it back
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// field describes one key of the configuration file
+type field struct {
+	key  string
+	help string
+	get  func(c *Config) string
+	set  func(c *Config, value string) error
+}
+
+var fields = []field{
+	{
+		key:  "hidden_folders",
+		help: "Carpetas que no se muestran (lista separada por comas)",
+		get:  func(c *Config) string { return strings.Join(c.HiddenFolders, ",") },
+		set: func(c *Config, value string) error {
+			c.HiddenFolders = splitList(value)
+			return validateNames(c.HiddenFolders)
+		},
+	},
+	{
+		key:  "ignore_prefixes",
+		help: "Prefijos de archivo que se ocultan (lista separada por comas)",
+		get:  func(c *Config) string { return strings.Join(c.IgnorePrefixes, ",") },
+		set: func(c *Config, value string) error {
+			c.IgnorePrefixes = splitList(value)
+			return nil
+		},
+	},
+	{
+		key:  "extensions",
+		help: "Extensiones o nombres de archivo que se listan (ver launcher interpreters)",
+		get:  func(c *Config) string { return strings.Join(c.Extensions, ",") },
+		set: func(c *Config, value string) error {
+			c.Extensions = splitList(value)
+			return validateExtensions(c.Extensions, c.Interpreters)
+		},
+	},
+	{
+		key:  "output_width",
+		help: "Ancho de ajuste de la salida (0 = ancho del terminal)",
+		get:  func(c *Config) string { return strconv.Itoa(c.OutputWidth) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("debe ser un número entero")
+			}
+			c.OutputWidth = n
+			return validateOutputWidth(n)
+		},
+	},
+	{
+		key:  "header",
+		help: "Cabecera ASCII: random, none o un archivo .txt de static/",
+		get:  func(c *Config) string { return c.Header },
+		set: func(c *Config, value string) error {
+			c.Header = strings.TrimSpace(value)
+			return validateHeader(c.Header)
+		},
+	},
+	{
+		key:  "dangerous_scripts",
+		help: "Scripts que piden confirmación escrita (nombres o rutas categoria/script, admite *)",
+		get:  func(c *Config) string { return strings.Join(c.DangerousScripts, ",") },
+		set: func(c *Config, value string) error {
+			c.DangerousScripts = splitList(value)
+			return validatePatterns(c.DangerousScripts)
+		},
+	},
+	{
+		key:  "detect_executables",
+		help: "Listar ejecutables con línea #! aunque su extensión no esté en extensions",
+		get:  func(c *Config) string { return strconv.FormatBool(c.DetectExecutables) },
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("debe ser true o false")
+			}
+			c.DetectExecutables = b
+			return nil
+		},
+	},
+	{
+		key:  "script_dirs",
+		help: "Carpetas de scripts de equipo (lista separada por comas)",
+		get:  func(c *Config) string { return strings.Join(c.ScriptDirs, ",") },
+		set: func(c *Config, value string) error {
+			c.ScriptDirs = splitList(value)
+			return nil
+		},
+	},
+	{
+		key:  "timeout",
+		help: "Tiempo máximo por defecto de un script (0 = sin límite; @timeout lo sustituye)",
+		get:  func(c *Config) string { return c.Timeout.String() },
+		set: func(c *Config, value string) error {
+			d, err := ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			c.Timeout = Duration(d)
+			return nil
+		},
+	},
+	{
+		key:  "kill_grace",
+		help: "Espera entre SIGINT y SIGKILL al cancelar un script",
+		get:  func(c *Config) string { return c.KillGrace.String() },
+		set: func(c *Config, value string) error {
+			d, err := ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			c.KillGrace = Duration(d)
+			return validateKillGrace(d)
+		},
+	},
+	{
+		key:  "run_mode",
+		help: "Modo de ejecución: terminal o capture (@mode lo sustituye)",
+		get:  func(c *Config) string { return c.RunMode },
+		set: func(c *Config, value string) error {
+			c.RunMode = strings.TrimSpace(value)
+			return validateRunMode(c.RunMode)
+		},
+	},
+}
+
+// Keys returns the configuration keys in file order
+func Keys() []string {
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// Help returns the description of a key
+func Help(key string) string {
+	if f, ok := lookup(key); ok {
+		return f.help
+	}
+	return ""
+}
+
+func lookup(key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// Get returns the value of a key as shown by "launcher config get"
+func (c Config) Get(key string) (string, error) {
+	f, ok := lookup(key)
+	if !ok {
+		return "", unknownKeyError(key)
+	}
+	return f.get(&c), nil
+}
+
+// Set parses and validates value for key. The config is left unchanged on error.
+func (c *Config) Set(key, value string) error {
+	f, ok := lookup(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	updated := *c
+	if err := f.set(&updated, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*c = updated
+	return nil
+}
+
+// Unset restores the default value of key
+func (c *Config) Unset(key string) error {
+	f, ok := lookup(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	def := Default()
+	return f.set(c, f.get(&def))
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("clave desconocida: %s (claves: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// Validate checks every key and returns one error per invalid value
+func (c Config) Validate() []error {
+	var errs []error
+	check := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	check("hidden_folders", validateNames(c.HiddenFolders))
+	for _, in := range c.Interpreters {
+		check("interpreters", in.Validate())
+	}
+	check("extensions", validateExtensions(c.Extensions, validInterpreters(c.Interpreters)))
+	check("output_width", validateOutputWidth(c.OutputWidth))
+	check("header", validateHeader(c.Header))
+	if c.Timeout < 0 {
+		check("timeout", fmt.Errorf("no puede ser negativo"))
+	}
+	check("dangerous_scripts", validatePatterns(c.DangerousScripts))
+	check("kill_grace", validateKillGrace(time.Duration(c.KillGrace)))
+	check("run_mode", validateRunMode(c.RunMode))
+	return errs
+}
+
+func validateNames(names []string) error {
+	for _, name := range names {
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("%q debe ser un nombre de carpeta, no una ruta", name)
+		}
+	}
+	return nil
+}
+
+func validateExtensions(extensions []string, custom []interpreter.Interpreter) error {
+	if len(extensions) == 0 {
+		return fmt.Errorf("debe incluir al menos una extensión")
+	}
+	registry := interpreter.NewRegistry(custom)
+	for _, ext := range extensions {
+		if !registry.Knows(ext) {
+			return fmt.Errorf("%q no tiene intérprete (soportadas: %s)", ext, strings.Join(registry.Extensions(), ", "))
+		}
+	}
+	return nil
+}
+
+// validInterpreters drops the user-defined interpreters that fail validation
+func validInterpreters(list []interpreter.Interpreter) []interpreter.Interpreter {
+	var valid []interpreter.Interpreter
+	for _, in := range list {
+		if in.Validate() == nil {
+			valid = append(valid, in)
+		}
+	}
+	return valid
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("patrón no válido %q", pattern)
+		}
+	}
+	return nil
+}
+
+func validateOutputWidth(width int) error {
+	if width != 0 && width < 20 {
+		return fmt.Errorf("debe ser 0 o al menos 20 (es %d)", width)
+	}
+	return nil
+}
+
+func validateHeader(header string) error {
+	switch {
+	case header == "":
+		return fmt.Errorf("no puede estar vacío (usa random o none)")
+	case header == HeaderRandom, header == HeaderNone:
+		return nil
+	case !strings.HasSuffix(header, ".txt") || strings.ContainsAny(header, `/\`):
+		return fmt.Errorf("%q debe ser random, none o el nombre de un archivo .txt de static/", header)
+	}
+	return nil
+}
+
+func validateKillGrace(d time.Duration) error {
+	if d <= 0 || d > time.Minute {
+		return fmt.Errorf("debe estar entre 1ms y 1m (es %s)", d)
+	}
+	return nil
+}
+
+func validateRunMode(mode string) error {
+	if mode != RunModeTerminal && mode != RunModeCapture {
+		return fmt.Errorf("%q debe ser %s o %s", mode, RunModeTerminal, RunModeCapture)
+	}
+	return nil
+}
+
+// ParseDuration accepts Go durations ("90s", "5m") or plain seconds
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, fmt.Errorf("no puede ser negativo")
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q no es una duración válida (ej. 90s, 5m o segundos)", value)
+	}
+	return d, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Load reads the config file over the defaults. A missing file is not an
+// error. Invalid values and unknown keys are reported and the affected keys
+// keep their default, so one bad line does not reset the others and the
+// launcher can still start.
+func Load(path string) (Config, []error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, []error{err}
+	}
+
+	name := filepath.Base(path)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return cfg, []error{fmt.Errorf("%s: %w", name, err)}
+	}
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return cfg, []error{fmt.Errorf("%s: se esperaba una lista de clave: valor", name)}
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if err := decodeKey(&cfg, key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %s: %w", name, key.Line, key.Value, err))
+		}
+	}
+
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		cfg.Interpreters = validInterpreters(cfg.Interpreters)
+		def := Default()
+		for _, f := range fields {
+			probe := cfg
+			if err := f.set(&probe, f.get(&cfg)); err != nil {
+				f.set(&cfg, f.get(&def))
+			}
+		}
+	}
+	return cfg, errs
+}
+
+var yamlLinePrefix = regexp.MustCompile(`^line \d+: `)
+
+// decodeKey decodes one "key: value" pair of the file into cfg, which is
+// left unchanged when the key is unknown or its value cannot be decoded
+func decodeKey(cfg *Config, key, value *yaml.Node) error {
+	if _, ok := lookup(key.Value); !ok && key.Value != "interpreters" {
+		return fmt.Errorf("clave desconocida (claves: %s)", strings.Join(Keys(), ", "))
+	}
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}})
+	if err != nil {
+		return err
+	}
+	probe := *cfg
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&probe); err != nil && !errors.Is(err, io.EOF) {
+		// Line numbers refer to the re-encoded pair, not to the file
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		msgs := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			msgs[i] = yamlLinePrefix.ReplaceAllString(msg, "")
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	*cfg = probe
+	return nil
+}
+
+// Save writes the config file
+func Save(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+var (
+	currentMu sync.RWMutex
+	current   = Default()
+)
+
+// Current returns the configuration loaded at startup
+func Current() Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// SetCurrent replaces the configuration used by the launcher
+func SetCurrent(cfg Config) {
+	currentMu.Lock()
+	current = cfg
+	currentMu.Unlock()
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetGetUnset(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("hidden_folders", " lib, vendor ,"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cfg.Get("hidden_folders"); got != "lib,vendor" {
+		t.Errorf("hidden_folders = %q", got)
+	}
+
+	before := cfg
+	for key, value := range map[string]string{
+		"output_width":   "10",
+		"hidden_folders": "a/b",
+		"header":         "logo.png",
+		"extensions":     "",
+	} {
+		if err := cfg.Set(key, value); err == nil || !strings.HasPrefix(err.Error(), key+": ") {
+			t.Errorf("Set(%q, %q) = %v, want an error about the key", key, value, err)
+		}
+	}
+	if !reflect.DeepEqual(cfg, before) {
+		t.Error("a failed Set changed the config")
+	}
+	if err := cfg.Set("nope", "1"); err == nil {
+		t.Error("Set accepted an unknown key")
+	}
+	if _, err := cfg.Get("nope"); err == nil {
+		t.Error("Get accepted an unknown key")
+	}
+
+	if err := cfg.Unset("hidden_folders"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.HiddenFolders, Default().HiddenFolders) {
+		t.Errorf("Unset left hidden_folders = %q", cfg.HiddenFolders)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, errs := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if len(errs) > 0 || !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load = %+v, %v; want the defaults", cfg, errs)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	cfg, errs := Load(writeConfig(t, "header: none\noutput_width: 120\nscript_dirs:\n  - ~/equipo\n"))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	want := Default()
+	want.Header = HeaderNone
+	want.OutputWidth = 120
+	want.ScriptDirs = []string{"~/equipo"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load =\n%+v\nwant\n%+v", cfg, want)
+	}
+}
+
+func TestLoadInvalidValueKeepsOtherKeys(t *testing.T) {
+	cfg, errs := Load(writeConfig(t, "header: none\noutput_width: 5\n"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "output_width") {
+		t.Errorf("errs = %v, want one about output_width", errs)
+	}
+	if cfg.OutputWidth != Default().OutputWidth {
+		t.Errorf("output_width = %d, want the default", cfg.OutputWidth)
+	}
+	if cfg.Header != HeaderNone {
+		t.Errorf("header = %q, want none", cfg.Header)
+	}
+}
+
+func TestLoadBadKeysKeepOtherKeys(t *testing.T) {
+	cfg, errs := Load(writeConfig(t, "header: none\ncolour: red\noutput_width: [1]\n"))
+	if len(errs) != 2 {
+		t.Fatalf("errs = %v, want two", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "config.yaml:2: colour") || !strings.Contains(errs[1].Error(), "config.yaml:3: output_width") {
+		t.Errorf("errs = %v, want file:line: key", errs)
+	}
+	if cfg.Header != HeaderNone || cfg.OutputWidth != Default().OutputWidth {
+		t.Errorf("Load = header %q, output_width %d", cfg.Header, cfg.OutputWidth)
+	}
+}
+
+func TestLoadDurations(t *testing.T) {
+	cfg, errs := Load(writeConfig(t, "timeout: 90\nkill_grace: 2s\n"))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if cfg.Timeout != Duration(90*time.Second) || cfg.KillGrace != Duration(2*time.Second) {
+		t.Errorf("timeout = %s, kill_grace = %s", cfg.Timeout, cfg.KillGrace)
+	}
+
+	cfg, errs = Load(writeConfig(t, "timeout: pronto\nkill_grace: 5m\n"))
+	if len(errs) != 2 {
+		t.Errorf("errs = %v, want two", errs)
+	}
+	if cfg.Timeout != Default().Timeout || cfg.KillGrace != Default().KillGrace {
+		t.Errorf("timeout = %s, kill_grace = %s; want the defaults", cfg.Timeout, cfg.KillGrace)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	cfg := Default()
+	for key, value := range map[string]string{
+		"header":       HeaderNone,
+		"output_width": "100",
+		"script_dirs":  "/srv/a, /srv/b",
+		"timeout":      "10m",
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "sub", "config.yaml")
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, errs := Load(path)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	for _, key := range Keys() {
+		got, _ := loaded.Get(key)
+		want, _ := cfg.Get(key)
+		if got != want {
+			t.Errorf("%s = %q after Save and Load, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package interpreter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Placeholders accepted in a command template. When the template has no
+// {script}, the script path is appended after the template.
+const (
+	PlaceholderScript = "{script}"
+	PlaceholderDir    = "{dir}"
+)
+
+// Interpreter describes how to run one kind of script
+type Interpreter struct {
+	Name string `yaml:"name"`
+	// Extensions (".py") and exact file names ("Makefile") handled by the interpreter
+	Extensions []string `yaml:"extensions"`
+	// Programs named in a #! line ("python3", "bash") that select the interpreter
+	Shebangs []string `yaml:"shebangs,omitempty"`
+	// Command template, e.g. [go, run, "{script}"]. Arguments go after it.
+	Command []string `yaml:"command"`
+	// Programs tried in order when Command[0] is not in the PATH
+	Fallbacks []string `yaml:"fallbacks,omitempty"`
+}
+
+// Builtins returns the interpreters known without any configuration
+func Builtins() []Interpreter {
+	return []Interpreter{
+		{Name: "bash", Extensions: []string{".sh", ".bash"}, Shebangs: []string{"bash"}, Command: []string{"bash"}},
+		{Name: "zsh", Extensions: []string{".zsh"}, Shebangs: []string{"zsh"}, Command: []string{"zsh"}},
+		{Name: "sh", Shebangs: []string{"sh", "dash", "ash"}, Command: []string{"sh"}},
+		{Name: "python", Extensions: []string{".py"}, Shebangs: []string{"python3", "python"}, Command: []string{"python3"}, Fallbacks: []string{"python"}},
+		{Name: "node", Extensions: []string{".js", ".mjs", ".cjs"}, Shebangs: []string{"node", "nodejs"}, Command: []string{"node"}},
+		{Name: "go", Extensions: []string{".go"}, Command: []string{"go", "run", PlaceholderScript}},
+		{Name: "pwsh", Extensions: []string{".ps1"}, Shebangs: []string{"pwsh", "powershell"}, Command: []string{"pwsh", "-ExecutionPolicy", "Bypass", "-File"}, Fallbacks: []string{"powershell"}},
+		{Name: "cmd", Extensions: []string{".bat", ".cmd"}, Command: []string{"cmd.exe", "/c"}}, // also from WSL
+		{Name: "make", Extensions: []string{".mk", "Makefile", "makefile", "GNUmakefile"}, Shebangs: []string{"make"}, Command: []string{"make", "-f", PlaceholderScript}},
+	}
+}
+
+// Registry resolves scripts to interpreters. User entries take precedence
+// over the built-ins: an entry with a built-in name replaces it, and its
+// extensions and shebangs are matched first.
+type Registry struct {
+	interpreters []Interpreter
+}
+
+// NewRegistry returns the built-ins extended with user-defined entries
+func NewRegistry(custom []Interpreter) *Registry {
+	r := &Registry{}
+	replaced := map[string]bool{}
+	for _, in := range custom {
+		r.interpreters = append(r.interpreters, in)
+		replaced[in.Name] = true
+	}
+	for _, in := range Builtins() {
+		if !replaced[in.Name] {
+			r.interpreters = append(r.interpreters, in)
+		}
+	}
+	return r
+}
+
+// All returns every interpreter in lookup order
+func (r *Registry) All() []Interpreter {
+	return r.interpreters
+}
+
+// Lookup returns the interpreter with the given name
+func (r *Registry) Lookup(name string) (Interpreter, bool) {
+	for _, in := range r.interpreters {
+		if in.Name == name {
+			return in, true
+		}
+	}
+	return Interpreter{}, false
+}
+
+// ForName returns the interpreter for a file name, by exact name first
+// ("Makefile") and then by extension
+func (r *Registry) ForName(name string) (Interpreter, bool) {
+	base := filepath.Base(name)
+	for _, in := range r.interpreters {
+		for _, ext := range in.Extensions {
+			if !strings.HasPrefix(ext, ".") && ext == base {
+				return in, true
+			}
+		}
+	}
+	ext := filepath.Ext(base)
+	if ext == "" {
+		return Interpreter{}, false
+	}
+	for _, in := range r.interpreters {
+		for _, known := range in.Extensions {
+			if strings.EqualFold(known, ext) {
+				return in, true
+			}
+		}
+	}
+	return Interpreter{}, false
+}
+
+// ForShebang returns the interpreter named by a #! line
+func (r *Registry) ForShebang(line string) (Interpreter, bool) {
+	program := ShebangProgram(line)
+	if program == "" {
+		return Interpreter{}, false
+	}
+	for _, in := range r.interpreters {
+		for _, name := range in.Shebangs {
+			if name == program {
+				return in, true
+			}
+		}
+	}
+	return Interpreter{}, false
+}
+
+// Resolve picks the interpreter of a script file: the one named by its #!
+// line when known, else by name or extension
+func (r *Registry) Resolve(path string) (Interpreter, bool) {
+	if in, ok := r.ForShebang(ReadShebang(path)); ok {
+		return in, true
+	}
+	return r.ForName(path)
+}
+
+// Knows reports whether an extensions entry of the config has an interpreter
+func (r *Registry) Knows(ext string) bool {
+	for _, in := range r.interpreters {
+		for _, known := range in.Extensions {
+			if strings.EqualFold(known, ext) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Extensions lists every extension and file name with an interpreter
+func (r *Registry) Extensions() []string {
+	var exts []string
+	seen := map[string]bool{}
+	for _, in := range r.interpreters {
+		for _, ext := range in.Extensions {
+			if !seen[strings.ToLower(ext)] {
+				seen[strings.ToLower(ext)] = true
+				exts = append(exts, ext)
+			}
+		}
+	}
+	return exts
+}
+
+// ReadShebang returns the first line of a file when it starts with #!
+func ReadShebang(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	return line
+}
+
+// ShebangProgram returns the program of a #! line, skipping /usr/bin/env
+// and its options: "#!/usr/bin/env -S python3 -u" gives "python3"
+func ShebangProgram(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	program := filepath.Base(fields[0])
+	if program == "env" {
+		program = ""
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			program = filepath.Base(field)
+			break
+		}
+	}
+	return program
+}
+
+// Program returns the first program of the interpreter found in the PATH
+func (in Interpreter) Program() (string, error) {
+	if len(in.Command) == 0 {
+		return "", fmt.Errorf("intérprete %s sin comando", in.Name)
+	}
+	candidates := append([]string{in.Command[0]}, in.Fallbacks...)
+	for _, candidate := range candidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("intérprete %s no disponible: %s no está en el PATH", in.Name, strings.Join(candidates, ", "))
+}
+
+// Available reports whether the interpreter can run on this machine
+func (in Interpreter) Available() bool {
+	_, err := in.Program()
+	return err == nil
+}
+
+// Argv builds the command line that runs script with args
+func (in Interpreter) Argv(script string, args []string) ([]string, error) {
+	program, err := in.Program()
+	if err != nil {
+		return nil, err
+	}
+
+	argv := []string{program}
+	hasScript := false
+	for _, token := range in.Command[1:] {
+		if strings.Contains(token, PlaceholderScript) {
+			hasScript = true
+		}
+		token = strings.ReplaceAll(token, PlaceholderScript, script)
+		token = strings.ReplaceAll(token, PlaceholderDir, filepath.Dir(script))
+		argv = append(argv, token)
+	}
+	if !hasScript {
+		argv = append(argv, script)
+	}
+	return append(argv, args...), nil
+}
+
+// Validate checks a user-defined entry
+func (in Interpreter) Validate() error {
+	switch {
+	case strings.TrimSpace(in.Name) == "":
+		return fmt.Errorf("intérprete sin name")
+	case len(in.Command) == 0 || strings.TrimSpace(in.Command[0]) == "":
+		return fmt.Errorf("intérprete %s sin command", in.Name)
+	case len(in.Extensions) == 0 && len(in.Shebangs) == 0:
+		return fmt.Errorf("intérprete %s necesita extensions o shebangs", in.Name)
+	}
+	for _, ext := range in.Extensions {
+		if ext == "" || strings.ContainsAny(ext, `/\`) {
+			return fmt.Errorf("intérprete %s: extensión no válida %q", in.Name, ext)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package interpreter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestForName(t *testing.T) {
+	r := NewRegistry(nil)
+	tests := map[string]string{
+		"a.py":             "python",
+		"dir/B.PY":         "python",
+		"run.sh":           "bash",
+		"Makefile":         "make",
+		"build.mk":         "make",
+		"tool.ps1":         "pwsh",
+		"notes.txt":        "",
+		"README":           "",
+		"Makefile.backup":  "",
+		"/srv/app/main.go": "go",
+	}
+	for name, want := range tests {
+		in, ok := r.ForName(name)
+		if ok != (want != "") || in.Name != want {
+			t.Errorf("ForName(%q) = %q, %v; want %q", name, in.Name, ok, want)
+		}
+	}
+}
+
+func TestCustomInterpreters(t *testing.T) {
+	r := NewRegistry([]Interpreter{
+		{Name: "python", Extensions: []string{".py"}, Command: []string{"uv", "run"}},
+		{Name: "ruby", Extensions: []string{".rb"}, Shebangs: []string{"ruby"}, Command: []string{"ruby"}},
+		{Name: "deno", Extensions: []string{".js"}, Command: []string{"deno", "run"}},
+	})
+
+	if in, _ := r.Lookup("python"); !reflect.DeepEqual(in.Command, []string{"uv", "run"}) {
+		t.Errorf("python was not replaced: %+v", in)
+	}
+	if in, ok := r.ForName("a.rb"); !ok || in.Name != "ruby" {
+		t.Errorf("ForName(a.rb) = %q, %v", in.Name, ok)
+	}
+	if in, _ := r.ForName("a.js"); in.Name != "deno" {
+		t.Errorf("ForName(a.js) = %q, want the user entry first", in.Name)
+	}
+	if !r.Knows(".RB") || r.Knows(".txt") {
+		t.Error("Knows does not follow the registry")
+	}
+
+	seen := map[string]bool{}
+	for _, ext := range r.Extensions() {
+		if seen[ext] {
+			t.Errorf("Extensions lists %q twice", ext)
+		}
+		seen[ext] = true
+	}
+	if !seen[".rb"] || !seen["Makefile"] {
+		t.Errorf("Extensions = %q", r.Extensions())
+	}
+}
+
+func TestShebangProgram(t *testing.T) {
+	tests := map[string]string{
+		"#!/bin/bash":                        "bash",
+		"#! /usr/bin/python3 -u":             "python3",
+		"#!/usr/bin/env node":                "node",
+		"#!/usr/bin/env -S python3 -u":       "python3",
+		"#!/usr/bin/env LANG=C.UTF-8 python": "python",
+		"#!/usr/bin/env":                     "",
+		"#!":                                 "",
+	}
+	for line, want := range tests {
+		if got := ShebangProgram(line); got != want {
+			t.Errorf("ShebangProgram(%q) = %q, want %q", line, got, want)
+		}
+	}
+
+	r := NewRegistry(nil)
+	if in, ok := r.ForShebang("#!/usr/bin/env python"); !ok || in.Name != "python" {
+		t.Errorf("ForShebang(python) = %q, %v", in.Name, ok)
+	}
+	if _, ok := r.ForShebang("#!/usr/bin/perl"); ok {
+		t.Error("ForShebang matched perl")
+	}
+}
+
+func TestReadShebang(t *testing.T) {
+	dir := t.TempDir()
+	for content, want := range map[string]string{
+		"#!/bin/sh\r\necho hi\n": "#!/bin/sh",
+		"#!/usr/bin/env node":    "#!/usr/bin/env node",
+		"echo hi\n#!/bin/sh\n":   "",
+		"":                       "",
+	} {
+		path := filepath.Join(dir, "script")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := ReadShebang(path); got != want {
+			t.Errorf("ReadShebang(%q) = %q, want %q", content, got, want)
+		}
+	}
+	if got := ReadShebang(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("ReadShebang(missing) = %q", got)
+	}
+}
+
+func TestArgv(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	script := filepath.Join("srv", "app", "main.go")
+	tests := []struct {
+		in   Interpreter
+		want []string
+	}{
+		{Interpreter{Command: []string{exe}}, []string{exe, script, "-v"}},
+		{Interpreter{Command: []string{exe, "run", PlaceholderScript}}, []string{exe, "run", script, "-v"}},
+		{Interpreter{Command: []string{exe, "-C", PlaceholderDir, "-f"}}, []string{exe, "-C", filepath.Dir(script), "-f", script, "-v"}},
+		{Interpreter{Command: []string{"devlauncher-missing-program"}, Fallbacks: []string{exe}}, []string{exe, script, "-v"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.in.Argv(script, []string{"-v"})
+		if err != nil {
+			t.Errorf("Argv(%q): %v", tt.in.Command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Argv(%q) = %q, want %q", tt.in.Command, got, tt.want)
+		}
+	}
+
+	missing := Interpreter{Name: "x", Command: []string{"devlauncher-missing-program"}}
+	if _, err := missing.Argv(script, nil); err == nil || missing.Available() {
+		t.Error("an interpreter outside the PATH is available")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Interpreter{Name: "ruby", Extensions: []string{".rb"}, Command: []string{"ruby"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+	for _, in := range []Interpreter{
+		{Extensions: []string{".rb"}, Command: []string{"ruby"}},
+		{Name: "ruby", Extensions: []string{".rb"}},
+		{Name: "ruby", Command: []string{"ruby"}},
+		{Name: "ruby", Extensions: []string{"bin/rb"}, Command: []string{"ruby"}},
+	} {
+		if err := in.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted an invalid entry", in)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.sh":  "#!/usr/bin/env python3\nprint(1)\n",
+		"b.py":  "print(1)\n",
+		"c":     "#!/bin/zsh\necho hi\n",
+		"d":     "echo hi\n",
+		"e.py":  "#!/usr/bin/perl\n",
+		"f.txt": "#!/bin/bash\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewRegistry(nil)
+	for name, want := range map[string]string{
+		"a.sh":  "python", // the #! line wins over the extension
+		"b.py":  "python",
+		"c":     "zsh",
+		"d":     "",
+		"e.py":  "python", // unknown #! program: by extension
+		"f.txt": "bash",
+	} {
+		in, ok := r.Resolve(filepath.Join(dir, name))
+		if ok != (want != "") || in.Name != want {
+			t.Errorf("Resolve(%s) = %q, %v; want %q", name, in.Name, ok, want)
+		}
+	}
+}
@@ -3,21 +3,54 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lucas/launcher/models"
 )
 
 func main() {
+	configErrs := models.LoadConfig()
+
 	// Parse CLI arguments
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "-h", "--help":
 			showHelp()
 			return
+		case "-v", "--version":
+			version, err := models.LauncherVersion(models.FindRootDir())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println("DevLauncher " + version.String())
+			return
 		case "-l", "--list":
-			models.ListAllScripts()
+			format, err := parseFormatFlag(os.Args[2:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			warnConfigErrors(configErrs)
+			if format == "" {
+				models.ListAllScripts()
+				return
+			}
+			if err := models.ExportScripts(os.Stdout, format); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
 			return
+		case "config":
+			os.Exit(models.ConfigCLI(os.Args[2:]))
+		case "run":
+			warnConfigErrors(configErrs)
+			os.Exit(models.RunScriptCLI(os.Args[2:]))
+		case "history":
+			os.Exit(models.HistoryCLI(os.Args[2:]))
+		case "interpreters":
+			os.Exit(models.InterpretersCLI(os.Args[2:]))
 		default:
 			fmt.Printf("Unknown option: %s\n", os.Args[1])
 			fmt.Println("Use --help to see available options")
@@ -28,6 +61,7 @@ func main() {
 	// Start interactive TUI with text selection support
 	// Using WithMouseAllMotion enables mouse scrolling while still allowing text selection
 	model := models.NewModel()
+	model.SetConfigErrors(configErrs)
 	p := tea.NewProgram(&model, tea.WithAltScreen(), tea.WithMouseAllMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
@@ -35,16 +69,74 @@ func main() {
 	}
 }
 
+// warnConfigErrors reports invalid config values on stderr (defaults are used instead)
+func warnConfigErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "Warning: invalid config:", err)
+	}
+}
+
+// parseFormatFlag reads "--format X" or "--format=X" from the --list arguments
+func parseFormatFlag(args []string) (string, error) {
+	format := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--format" || arg == "-f":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("--format requires a value (json, yaml, tsv)")
+			}
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		default:
+			return "", fmt.Errorf("unknown option for --list: %s", arg)
+		}
+	}
+	return format, nil
+}
+
 func showHelp() {
 	fmt.Println("Launcher - Universal Development Scripts Launcher")
 	fmt.Println()
 	fmt.Println("Usage: launcher [options]")
+	fmt.Println("       launcher run [--skip-checks] [--yes] <category/path/script> [-- args...]")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  (no options)    Show interactive hierarchical menu")
 	fmt.Println("  -l, --list      List all organized scripts")
+	fmt.Println("    --format F    Machine-readable listing of the whole tree: json, yaml or tsv")
+	fmt.Println("  -v, --version   Show the launcher version (from VERSION.txt)")
 	fmt.Println("  -h, --help      Show this help")
 	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  run <script>    Run a script (or a .workflow.yaml) by its path inside the merged scripts tree")
+	fmt.Println("                  (extension optional, e.g. instaladores/instalar_go).")
+	fmt.Println("                  A bare name is searched in every folder.")
+	fmt.Println("                  Arguments after -- are passed to the script and")
+	fmt.Println("                  the script exit code is returned.")
+	fmt.Println("    --skip-checks Run even if @requires checks fail (otherwise exit 3)")
+	fmt.Println("    --yes, -y     Do not ask before @confirm/dangerous scripts (otherwise exit 4)")
+	fmt.Println("  history         List recent runs (TUI and CLI)")
+	fmt.Println("    list [-n N]   Show the last N runs (default 20)")
+	fmt.Println("    show <N>      Show details and saved output of run N")
+	fmt.Println("    rerun <N>     Run entry N again with the same args and directory")
+	fmt.Println("                  (same checks as run: --skip-checks, --yes, exit 3 or 4)")
+	fmt.Println("    clear         Delete the history")
+	fmt.Println("  config          Show the configuration (~/.config/devlauncher/config.yaml)")
+	fmt.Println("    get <key>     Print one value")
+	fmt.Println("    set <k> <v>   Validate and save a value (lists are comma-separated)")
+	fmt.Println("    unset <key>   Restore the default value")
+	fmt.Println("    path          Print the config file path")
+	fmt.Println("  interpreters    List the script interpreters and whether they are installed")
+	fmt.Println()
+	fmt.Println("Script roots (later ones override earlier ones):")
+	fmt.Println("  install         scripts/<platform> next to the launcher")
+	fmt.Println("  user            ~/.config/devlauncher/scripts")
+	fmt.Println("  team            script_dirs in the config file and DEVLAUNCHER_SCRIPT_DIRS")
+	fmt.Println("  project         Nearest .devlauncher/scripts above the current directory, below $HOME")
+	fmt.Println()
 	fmt.Println("Navigation:")
 	fmt.Println("  1. Select a category (build, dev, installers, etc.)")
 	fmt.Println("  2. Select a script within the category")
@@ -53,6 +145,11 @@ func showHelp() {
 	fmt.Println("Controls:")
 	fmt.Println("  ↑/↓ or j/k      Navigate")
 	fmt.Println("  Enter           Select")
+	fmt.Println("  H               Execution history")
+	fmt.Println("  b               Run the selected script in the background")
+	fmt.Println("  J               Background jobs")
+	fmt.Println("  /, n/N          Search the output of a finished script (result screen)")
+	fmt.Println("  w, s, c         Toggle wrap, save to a .log file, copy with OSC 52 (result screen)")
 	fmt.Println("  Esc or q        Back/Quit")
 	fmt.Println()
 }
@@ -2,12 +2,17 @@ package models
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/lucas/launcher/config"
 	"github.com/lucas/launcher/ui"
 	"github.com/lucas/launcher/utils"
 )
@@ -18,8 +23,15 @@ type ViewState int
 const (
 	CategoryView ViewState = iota
 	ScriptView
+	ParamFormView
 	ExecutingView
 	ResultView
+	HistoryView
+	FinderView
+	PreflightView
+	ConfirmView
+	JobsView
+	WorkflowView
 )
 
 // Model is the Bubbletea application model
@@ -28,6 +40,7 @@ type Model struct {
 	rootDir          string
 	staticDir        string
 	scriptsRoot      string
+	roots            []ScriptRoot  // Script roots, lowest precedence first
 	launchDir        string
 	runDir           string
 	currentVersion   string
@@ -39,41 +52,59 @@ type Model struct {
 	categoryList     list.Model
 	scriptList       list.Model
 	commandMode      CommandMode
+	paramForm        ParamForm
+	returnState      ViewState  // View to go back to from ResultView
+	historyPath      string
+	history          []HistoryEntry
+	historyIndex     int
+	historyReturn    ViewState  // View that opened HistoryView
+	favoritesPath    string
+	favorites        []string   // Starred script and folder paths
+	finder           Finder
+	finderReturn     ViewState  // View that opened the finder
+	preflightChecks  []requirementCheck // Report shown in PreflightView
+	preflightReturn  ViewState  // View to go back to when the run is aborted
+	preflightRerun   *HistoryEntry // History entry waiting in PreflightView
+	confirmGate      ConfirmGate
+	confirmReturn    ViewState  // View that launched the run waiting in ConfirmView
+	envOverrides     []string   // KEY=VALUE set with the env command, applied to every run
+	background       bool       // Run the script being launched as a job (key b)
+	jobs             []*Job     // Background runs, oldest first
+	nextJobID        int
+	jobIndex         int
+	jobsReturn       ViewState  // View that opened JobsView
+	jobsTicking      bool
+	quitPending      bool       // Quit was requested while jobs were running
+	workflow         *workflowRun  // Workflow shown in WorkflowView
+	workflowErr      error         // Why the workflow could not start
+	workflowActive   *scriptRun    // Step currently running, if any
+	workflowIndex    int           // Selected step
+	workflowReturn   ViewState     // View that launched the workflow
 	err              error
 	executing        bool
 	executionResult  int
+	executionStatus  string  // RunOK, RunFailed, RunCancelled or RunTimedOut
+	executionTime    time.Duration
+	activeRun        *scriptRun  // Script currently running, if any
 	executionOutput  string  // Full stdout+stderr from script execution
-	outputScroll     int     // Scroll position for output view
+	resultViewer     OutputViewer // Output shown in ResultView
+	diagnoses        []Diagnosis  // Known errors found in a failed output
+	diagnosisErrs    []error      // Invalid diagnostics.yaml files
 	width            int
 	height           int
 	headerShown      bool
 	header           string  // Cached header (loaded once)
+	configErrors     []error // Invalid config values, shown in CategoryView
 }
 
 // NewModel creates a new application model
 func NewModel() Model {
 	// Get root directory - try multiple strategies
-	var rootDir string
+	rootDir := FindRootDir()
 	launchDir := ""
 	if cwd, err := os.Getwd(); err == nil {
 		launchDir = cwd
 	}
-	
-	// Strategy 1: Check if scripts/ exists in parent of current dir
-	if cwd, err := os.Getwd(); err == nil {
-		if _, err := os.Stat(filepath.Join(cwd, "..", "scripts")); err == nil {
-			rootDir, _ = filepath.Abs("..")
-		} else if _, err := os.Stat(filepath.Join(cwd, "scripts")); err == nil {
-			rootDir = cwd
-		}
-	}
-	
-	// Strategy 2: Use executable path
-	if rootDir == "" {
-		execPath, _ := os.Executable()
-		realPath, _ := filepath.EvalSymlinks(execPath)
-		rootDir = filepath.Dir(realPath)
-	}
 	if strings.TrimSpace(launchDir) == "" {
 		launchDir = rootDir
 	}
@@ -87,18 +118,26 @@ func NewModel() Model {
 		rootDir:     rootDir,
 		staticDir:   staticDir,
 		scriptsRoot: scriptsRoot,
+		roots:       ResolveScriptRoots(rootDir, launchDir),
 		launchDir:   launchDir,
 		runDir:      launchDir,
 		currentVersion: currentVersion,
+		historyPath: utils.GetHistoryPath(),
+		favoritesPath: utils.GetFavoritesPath(),
 		commandMode: NewCommandMode(),
 		width:       80,
 		height:      24,
 	}
 }
 
+// SetConfigErrors shows the configuration errors found at startup
+func (m *Model) SetConfigErrors(errs []error) {
+	m.configErrors = errs
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
-	return loadCategories(m.rootDir)
+	return loadCategories(m.roots, m.favoritesPath)
 }
 
 // Update handles messages
@@ -108,6 +147,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.commandMode.SetSize(msg.Width, msg.Height)
+		m.resultViewer.SetSize(msg.Width, m.resultHeight())
 		return m, nil
 
 	case tea.MouseMsg:
@@ -117,11 +157,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle mouse wheel in result view for scrolling
 		if m.state == ResultView {
 			if msg.Type == tea.MouseWheelUp {
-				if m.outputScroll > 0 {
-					m.outputScroll--
-				}
+				m.resultViewer.ScrollBy(-3)
 			} else if msg.Type == tea.MouseWheelDown {
-				m.outputScroll++
+				m.resultViewer.ScrollBy(3)
 			}
 		}
 		return m, nil
@@ -149,6 +187,121 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// The parameter form owns the keyboard while it is open
+		if m.state == ParamFormView {
+			if msg.String() == "ctrl+c" {
+				return m, m.quit()
+			}
+			opts, submitted, cancelled, cmd := m.paramForm.Update(msg)
+			if cancelled {
+				m.background = false
+				m.state = ScriptView
+				return m, nil
+			}
+			if submitted {
+				return m, m.runScript(m.currentScript, opts)
+			}
+			return m, cmd
+		}
+
+		// The pre-flight report only accepts continue or abort
+		if m.state == PreflightView {
+			switch msg.String() {
+			case "c", "y", "s":
+				m.state = m.preflightReturn
+				if entry := m.preflightRerun; entry != nil {
+					m.preflightRerun = nil
+					return m, m.runScriptIn(entry.ScriptRef(), entry.RunDir, entry.RunOptions())
+				}
+				return m, m.continueScript(m.currentScript)
+			case "/":
+				return m, m.openFinder("")
+			case "esc", "n", ".", "0":
+				m.background = false
+				m.preflightRerun = nil
+				m.state = m.preflightReturn
+			case "ctrl+c", "q":
+				return m, m.quit()
+			}
+			return m, nil
+		}
+
+		// The confirmation gate owns the keyboard until it is answered
+		if m.state == ConfirmView {
+			if msg.String() == "ctrl+c" {
+				return m, m.quit()
+			}
+			accepted, cancelled, cmd := m.confirmGate.Update(msg)
+			if cancelled {
+				m.background = false
+				m.state = m.confirmReturn
+				return m, nil
+			}
+			if accepted {
+				m.state = m.confirmReturn
+				g := m.confirmGate
+				return m, m.startRun(g.script, g.runDir, g.opts)
+			}
+			return m, cmd
+		}
+
+		// The finder owns the keyboard while it is open
+		if m.state == FinderView {
+			if msg.String() == "ctrl+c" {
+				return m, m.quit()
+			}
+			picked, folderOnly, closed, cmd := m.finder.Update(msg)
+			if closed {
+				m.state = m.finderReturn
+				return m, nil
+			}
+			if picked {
+				item, _ := m.finder.Selected()
+				return m, m.pickSearchResult(item, folderOnly)
+			}
+			return m, cmd
+		}
+
+		// While a captured script runs only cancellation keys are handled
+		if m.state == ExecutingView {
+			switch msg.String() {
+			case "x", "ctrl+c":
+				if m.activeRun != nil {
+					m.activeRun.Cancel()
+				}
+			}
+			return m, nil
+		}
+
+		if m.state == HistoryView {
+			if cmd, handled := m.updateHistoryView(msg); handled {
+				return m, cmd
+			}
+		}
+
+		if m.state == JobsView {
+			if cmd, handled := m.updateJobsView(msg); handled {
+				return m, cmd
+			}
+		}
+
+		if m.state == WorkflowView {
+			if cmd, handled := m.updateWorkflowView(msg); handled {
+				return m, cmd
+			}
+		}
+
+		// The output viewer gets the keys first, / searches the output
+		if m.state == ResultView && msg.String() != "ctrl+c" {
+			if msg.String() == "s" && !m.resultViewer.Searching() {
+				m.resultViewer.Save(m.runDir, m.currentScript.Name)
+				return m, nil
+			}
+			if cmd, handled := m.resultViewer.Update(msg); handled {
+				return m, cmd
+			}
+		}
+
 		switch msg.String() {
 		case ":":
 			// Activate command mode with ':'
@@ -157,104 +310,95 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.commandMode.input.Focus()
 			return m, nil
 		
+		case "/":
+			// Fuzzy search across the whole scripts tree, from any view that
+			// gets here (ResultView keeps / to search its output, above)
+			return m, m.openFinder("")
+
 		case ".":
 			// '.' goes back one level
 			if m.state == ScriptView {
-				if m.currentPath != "" && m.currentPath != m.currentCategory.Path {
-					m.currentPath = filepath.Dir(m.currentPath)
-					return m, loadScripts(m.currentPath)
-				}
-				m.state = CategoryView
-				return m, nil
+				return m, m.goBack()
 			} else if m.state == ResultView {
-				m.state = ScriptView
-				return m, nil
+				return m, m.leaveResult()
 			} else if m.state == CategoryView {
-				return m, tea.Quit  // Exit from main menu
+				return m, m.quit()  // Exit from main menu
 			}
 		
 		case "ctrl+c", "q":
-			// Always quit regardless of state
-			return m, tea.Quit
+			// Always quit regardless of state (running jobs are shown first)
+			return m, m.quit()
+
+		case "f":
+			// Star/unstar the selected script or folder
+			if m.state == ScriptView && len(m.scripts) > 0 {
+				if i := m.scriptList.Index(); i >= 0 && i < len(m.scripts) {
+					cmd, _ := m.toggleFavorite(m.scripts[i])
+					return m, cmd
+				}
+			}
+
+		case "H":
+			// Open execution history
+			if m.state == CategoryView || m.state == ScriptView {
+				m.historyReturn = m.state
+				return m, m.openHistory()
+			}
+
+		case "J":
+			// Open the background jobs
+			if m.state == CategoryView || m.state == ScriptView || m.state == HistoryView {
+				return m, m.openJobs()
+			}
+
+		case "b":
+			// Run the selected script in the background
+			if m.state == ScriptView && len(m.scripts) > 0 {
+				if i := m.scriptList.Index(); i >= 0 && i < len(m.scripts) && m.scripts[i].Extension != ".dir" {
+					m.background = true
+					return m, m.startScript(m.scripts[i])
+				}
+			}
 
 		case "esc", "0":
 			// Go back one level (or quit from main menu)
 			if m.state == ScriptView {
-				if m.currentPath != "" && m.currentPath != m.currentCategory.Path {
-					m.currentPath = filepath.Dir(m.currentPath)
-					return m, loadScripts(m.currentPath)
-				}
-				m.state = CategoryView
-				return m, nil
+				return m, m.goBack()
 			} else if m.state == ResultView {
-				m.state = ScriptView
-				return m, nil
+				return m, m.leaveResult()
 			} else if m.state == CategoryView {
-				return m, tea.Quit
+				return m, m.quit()
 			}
 
 		case "enter":
 			if m.state == CategoryView && len(m.categories) > 0 {
 				// Get selected category
 				if i, ok := m.categoryList.SelectedItem().(categoryItem); ok {
-					m.currentCategory = m.categories[i.index]
-					m.currentPath = m.currentCategory.Path
-					m.state = ScriptView
-					m.headerShown = true  // Mark header as shown when leaving CategoryView
-					return m, loadScripts(m.currentPath)
+					return m, m.openCategory(m.categories[i.index])
 				}
 			} else if m.state == ScriptView && len(m.scripts) > 0 {
 				// Get selected script
 				if i, ok := m.scriptList.SelectedItem().(scriptItem); ok {
-					m.currentScript = m.scripts[i.index]
-					if m.currentScript.Extension == ".dir" {
-						m.currentPath = m.currentScript.Path
-						return m, loadScripts(m.currentPath)
-					}
-					m.state = ExecutingView
-					m.outputScroll = 0  // Reset scroll position
-					return m, executeScript(m.currentScript, m.runDir)
+					return m, m.openItem(m.scripts[i.index])
 				}
 			} else if m.state == ResultView {
-				// Return to script view after seeing result
-				m.state = ScriptView
-				return m, nil
-			}
-		
-		// Arrow keys for scrolling in result view
-		case "up", "k":
-			if m.state == ResultView && m.outputScroll > 0 {
-				m.outputScroll--
-				return m, nil
-			}
-		case "down", "j":
-			if m.state == ResultView {
-				m.outputScroll++
-				return m, nil
+				// Return to the view that launched the script
+				return m, m.leaveResult()
 			}
 		
 		// Number keys for quick selection
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			num := int(msg.String()[0] - '0') - 1
 			if m.state == CategoryView && num >= 0 && num < len(m.categories) {
-				m.currentCategory = m.categories[num]
-				m.currentPath = m.currentCategory.Path
-				m.state = ScriptView
-				m.headerShown = true  // Mark header as shown when leaving CategoryView
-				return m, loadScripts(m.currentPath)
+				return m, m.openCategory(m.categories[num])
 			} else if m.state == ScriptView && num >= 0 && num < len(m.scripts) {
-				m.currentScript = m.scripts[num]
-				if m.currentScript.Extension == ".dir" {
-					m.currentPath = m.currentScript.Path
-					return m, loadScripts(m.currentPath)
-				}
-				m.state = ExecutingView
-				return m, executeScript(m.currentScript, m.runDir)
+				return m, m.openItem(m.scripts[num])
 			}
 		}
 
 	case categoriesLoadedMsg:
-		m.categories = msg.categories
+		m.favorites = msg.favorites
+		m.categories = m.withFavorites(msg.categories)
 		m.categoryList = m.createCategoryList()
 		return m, nil
 
@@ -263,11 +407,49 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.scriptList = m.createScriptList()
 		return m, nil
 
+	case execTickMsg:
+		if m.state == ExecutingView && m.activeRun != nil {
+			return m, execTick()
+		}
+		if m.state == WorkflowView && m.workflowActive != nil {
+			return m, execTick()
+		}
+		return m, nil
+
+	case jobsTickMsg:
+		if m.state == JobsView && m.runningJobs() > 0 {
+			return m, jobsTick()
+		}
+		m.jobsTicking = false
+		return m, nil
+
+	case jobFinishedMsg:
+		return m, m.finishJob(msg)
+
+	case workflowStepMsg:
+		return m, m.finishWorkflowStep(msg)
+
 	case scriptExecutedMsg:
+		m.activeRun = nil
 		m.executionResult = msg.exitCode
+		m.executionStatus = msg.entry.Status
+		m.executionTime = msg.entry.Duration
 		m.executionOutput = msg.output
+		m.showResult()
 		m.executing = false
 		m.state = ResultView
+		return m, saveHistory(m.historyPath, msg.entry)
+
+	case historyLoadedMsg:
+		m.history = msg.entries
+		m.historyIndex = 0
+		m.state = HistoryView
+		return m, nil
+
+	case searchIndexMsg:
+		if m.state == FinderView {
+			m.finder.SetItems(msg.items)
+		}
 		return m, nil
 
 	case errorMsg:
@@ -286,6 +468,168 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// openCategory enters a category (or the Favoritos pseudo-category)
+func (m *Model) openCategory(cat Category) tea.Cmd {
+	m.currentCategory = cat
+	m.currentPath = cat.Path
+	m.state = ScriptView
+	m.headerShown = true // Mark header as shown when leaving CategoryView
+	return m.loadCurrentFolder()
+}
+
+// goBack goes up one folder in ScriptView, or back to CategoryView
+func (m *Model) goBack() tea.Cmd {
+	if m.currentCategory.Favorites && m.currentPath != "" {
+		// Inside a starred folder: go up until the starred folder itself
+		if m.isFavorite(m.currentPath) || !m.insideFavorite(m.currentPath) {
+			m.currentPath = ""
+			return loadFavoriteScripts(m.favorites)
+		}
+		m.currentPath = filepath.Dir(m.currentPath)
+		return loadScripts(m.currentPath)
+	}
+	if rel, ok := folderRel(m.currentCategory, m.currentPath); ok && rel != "." {
+		// The parent may exist in more roots than the current folder
+		m.currentPath = filepath.Dir(m.currentPath)
+		if sources := folderSources(m.currentCategory, path.Dir(rel)); len(sources) > 0 {
+			m.currentPath = sources[0].Path
+		}
+		return m.loadCurrentFolder()
+	}
+	m.state = CategoryView
+	return nil
+}
+
+// openItem enters a folder or starts a script from the current script list
+func (m *Model) openItem(script Script) tea.Cmd {
+	if script.Extension == ".dir" {
+		m.currentScript = script
+		m.currentPath = script.Path
+		return m.loadCurrentFolder()
+	}
+	return m.startScript(script)
+}
+
+// startScript runs a script, asking for its declared parameters first
+func (m *Model) startScript(script Script) tea.Cmd {
+	m.currentScript = script
+	m.preflightRerun = nil
+	if m.openPreflight(script) {
+		return nil
+	}
+	return m.continueScript(script)
+}
+
+// openPreflight shows the pre-flight report when some requirement of the
+// script is not met. It returns false when the script can run right away.
+func (m *Model) openPreflight(script Script) bool {
+	if !script.hasRequirements() {
+		return false
+	}
+	checks := checkRequirements(script)
+	if len(unmetRequirements(checks)) == 0 {
+		return false
+	}
+	m.preflightChecks = checks
+	if m.state != PreflightView {
+		m.preflightReturn = m.state
+	}
+	m.state = PreflightView
+	return true
+}
+
+// continueScript asks for the script parameters, if any, and runs it
+func (m *Model) continueScript(script Script) tea.Cmd {
+	if len(script.Params) > 0 {
+		m.paramForm = NewParamForm(script)
+		m.state = ParamFormView
+		return textinput.Blink
+	}
+	return m.runScript(script, RunOptions{})
+}
+
+// runScript switches to the executing view and hands the terminal to the script
+func (m *Model) runScript(script Script, opts RunOptions) tea.Cmd {
+	return m.runScriptIn(script, m.runDir, opts)
+}
+
+// runScriptIn is runScript with an explicit run directory (used by history
+// re-runs). Scripts marked @confirm or dangerous wait in ConfirmView first.
+func (m *Model) runScriptIn(script Script, runDir string, opts RunOptions) tea.Cmd {
+	script = withEnvContext(m.categories, script)
+	if script.IsWorkflow() {
+		// Confirmed once for the whole chain, as dangerous as its worst step
+		if w, err := planWorkflow(m.categories, script, runDir); err == nil {
+			script = w.gateScript(m.categories)
+		}
+	}
+	opts.Env = append(append([]string(nil), opts.Env...), m.envOverrides...)
+	if m.openConfirm(script, runDir, opts) {
+		if m.confirmGate.typed {
+			return textinput.Blink
+		}
+		return nil
+	}
+	return m.startRun(script, runDir, opts)
+}
+
+// startRun switches to the executing view and runs the script, or starts it
+// as a job when it was launched with b or declares @mode background
+func (m *Model) startRun(script Script, runDir string, opts RunOptions) tea.Cmd {
+	if script.IsWorkflow() {
+		m.background = false
+		return m.startWorkflow(script, runDir)
+	}
+	if m.background || runMode(script) == config.RunModeBackground {
+		m.background = false
+		if m.state == ParamFormView {
+			m.state = ScriptView
+		}
+		return m.startJob(script, runDir, opts)
+	}
+	if m.state != ParamFormView {
+		m.returnState = m.state
+	} else {
+		m.returnState = ScriptView
+	}
+	m.currentScript = script
+	m.state = ExecutingView
+	m.executing = true
+	return m.executeScript(script, runDir, opts)
+}
+
+// showResult loads executionOutput into the ResultView viewer and, when
+// the run failed, looks for known errors in it
+func (m *Model) showResult() {
+	m.resultViewer = NewOutputViewer(m.executionOutput)
+	m.diagnoses, m.diagnosisErrs = nil, nil
+	if m.executionResult != 0 && m.executionStatus != RunCancelled {
+		var rules []DiagnosisRule
+		rules, m.diagnosisErrs = LoadDiagnosisRules(m.roots)
+		m.diagnoses = Diagnose(rules, m.resultViewer.plain)
+	}
+	m.resultViewer.SetSize(m.width, m.resultHeight())
+}
+
+// resultHeight is the number of output rows that fit in ResultView
+func (m *Model) resultHeight() int {
+	return m.height - 9 - strings.Count(renderDiagnoses(m.diagnoses, m.diagnosisErrs), "\n")
+}
+
+// leaveResult returns from ResultView to the view that launched the script
+func (m *Model) leaveResult() tea.Cmd {
+	m.state = m.returnState
+	m.refreshRequirements()
+	if m.state == HistoryView {
+		// Show the run that just finished
+		return m.openHistory()
+	}
+	if m.state == JobsView {
+		return m.openJobs()
+	}
+	return nil
+}
+
 // View renders the UI
 func (m *Model) View() string {
 	switch m.state {
@@ -293,10 +637,24 @@ func (m *Model) View() string {
 		return m.renderCategoryView()
 	case ScriptView:
 		return m.renderScriptView()
+	case ParamFormView:
+		return m.renderParamFormView()
 	case ExecutingView:
 		return m.renderExecutingView()
 	case ResultView:
 		return m.renderResultView()
+	case HistoryView:
+		return m.renderHistoryView()
+	case FinderView:
+		return m.renderFinderView()
+	case PreflightView:
+		return m.renderPreflightView()
+	case ConfirmView:
+		return m.renderConfirmView()
+	case JobsView:
+		return m.renderJobsView()
+	case WorkflowView:
+		return m.renderWorkflowView()
 	}
 
 	return ""
@@ -305,7 +663,7 @@ func (m *Model) View() string {
 func (m *Model) renderCategoryView() string {
 	// Load and cache header on first render
 	if m.header == "" && len(m.categories) > 0 {
-		m.header = decorateHeaderWithVersion(ui.LoadASCIIArt(m.staticDir), m.currentVersion)
+		m.header = decorateHeaderWithVersion(loadHeader(m.staticDir), m.currentVersion)
 	}
 	
 	// Show header while in CategoryView (until user navigates away)
@@ -317,6 +675,13 @@ func (m *Model) renderCategoryView() string {
 	breadcrumb := ui.RenderBreadcrumb([]string{"Inicio"}, m.runDir)
 	
 	content := header + breadcrumb
+
+	for _, err := range m.configErrors {
+		content += ui.ErrorStyle.Render("✗ Configuración inválida: ") + ui.DimStyle.Render(err.Error()) + "\n"
+	}
+	if len(m.configErrors) > 0 {
+		content += ui.DimStyle.Render("  Se usan los valores por defecto ("+utils.GetConfigPath()+")") + "\n\n"
+	}
 	
 	if len(m.categories) == 0 {
 		content += ui.ErrorStyle.Render("✗ No se encontraron categorías") + "\n"
@@ -325,7 +690,10 @@ func (m *Model) renderCategoryView() string {
 		content += m.renderCategoriesWithNumbers()
 	}
 	
-	content += "\n" + ui.DimStyle.Render("1-9/↑↓/j/k: navegar  enter/número: seleccionar  :: terminal  ./0/esc: volver  q: salir")
+	if summary := m.jobsSummary(); summary != "" {
+		content += "\n" + summary
+	}
+	content += "\n" + ui.DimStyle.Render("1-9/↑↓/j/k: navegar  enter/número: seleccionar  /: buscar  :: terminal  H: historial  J: jobs  ./0/esc: volver  q: salir")
 	
 	if m.commandMode.active {
 		content += m.commandMode.View()
@@ -346,6 +714,9 @@ func (m Model) renderCategoriesWithNumbers() string {
 		label := fmt.Sprintf("%s %s/", cat.Icon, cat.Name)
 		prefix := fmt.Sprintf("  [%d] ", num)
 		counts := formatCategoryCounts(cat.DirCount, cat.ScriptCount)
+		if badge := originBadge(sourceOrigins(cat.Sources)...); badge != "" {
+			counts += "  " + badge
+		}
 		var styledLabel string
 		if selected {
 			styledLabel = ui.SelectedDirectoryStyle.Render(label)
@@ -391,9 +762,10 @@ func formatCategoryCounts(dirCount, scriptCount int) string {
 
 func (m Model) renderScriptView() string {
 	breadcrumbParts := []string{"Inicio", m.currentCategory.Name}
-	if m.currentPath != "" {
-		if rel, err := filepath.Rel(m.currentCategory.Path, m.currentPath); err == nil {
-			rel = filepath.ToSlash(rel)
+	if m.currentCategory.Favorites && m.currentPath != "" {
+		breadcrumbParts = append(breadcrumbParts, filepath.Base(m.currentPath))
+	} else if m.currentPath != "" {
+		if rel, ok := folderRel(m.currentCategory, m.currentPath); ok {
 			if rel != "." && rel != "" {
 				for _, p := range strings.Split(rel, "/") {
 					if strings.TrimSpace(p) != "" {
@@ -419,7 +791,10 @@ func (m Model) renderScriptView() string {
 		content += m.renderScriptsWithNumbers()
 	}
 	
-	content += "\n" + ui.DimStyle.Render("1-9/↑↓/j/k: navegar  enter/número: abrir/ejecutar  :: terminal  ./0/esc: volver  q: salir")
+	if summary := m.jobsSummary(); summary != "" {
+		content += "\n" + summary
+	}
+	content += "\n" + ui.DimStyle.Render("1-9/↑↓/j/k: navegar  enter/número: abrir/ejecutar  b: en segundo plano  f: favorito  /: buscar  :: terminal  H: historial  J: jobs  ./0/esc: volver  q: salir")
 	
 	if m.commandMode.active {
 		content += m.commandMode.View()
@@ -436,7 +811,7 @@ func (m Model) renderScriptsWithNumbers() string {
 		}
 		num := i + 1
 		selected := m.scriptList.Index() == i
-		label := script.Name
+		label := script.DisplayName()
 		isDir := script.Extension == ".dir"
 		counts := ""
 		if script.Extension == ".dir" {
@@ -446,6 +821,29 @@ func (m Model) renderScriptsWithNumbers() string {
 			}
 			label = fmt.Sprintf("%s %s/", icon, script.Name)
 			counts = formatCategoryCounts(script.DirCount, script.ScriptCount)
+		} else if script.IsWorkflow() {
+			label = fmt.Sprintf("%s %s", script.Icon, script.DisplayName())
+			counts = "workflow"
+			if script.ScriptCount > 0 {
+				counts += fmt.Sprintf(" · %d pasos", script.ScriptCount)
+			}
+		} else {
+			counts = interpreterLabel(script)
+		}
+
+		if m.isFavorite(script.Path) {
+			label += " ★"
+		}
+
+		badge := originBadge(script.Origin)
+		if isDir {
+			badge = originBadge(sourceOrigins(script.Sources)...)
+		}
+		if badge != "" {
+			if counts != "" {
+				counts += "  "
+			}
+			counts += badge
 		}
 
 		prefix := fmt.Sprintf("  [%d] ", num)
@@ -462,6 +860,9 @@ func (m Model) renderScriptsWithNumbers() string {
 			} else {
 				styledLabel = ui.ExecutableStyle.Render(label)
 			}
+			if len(script.Unmet) > 0 {
+				styledLabel += ui.WarningStyle.Render(" ⚠")
+			}
 		}
 
 		if selected {
@@ -470,8 +871,8 @@ func (m Model) renderScriptsWithNumbers() string {
 				line += "      " + ui.CountStyle.Render(counts)
 			}
 			result += line + "\n"
-			if script.Description != "" {
-				result += ui.DimStyle.Render(fmt.Sprintf("      %s", script.Description)) + "\n"
+			if details := scriptDetails(script); details != "" {
+				result += ui.DimStyle.Render(fmt.Sprintf("      %s", details)) + "\n"
 			}
 		} else {
 			line := ui.NormalStyle.Render(prefix) + styledLabel
@@ -479,19 +880,90 @@ func (m Model) renderScriptsWithNumbers() string {
 				line += "      " + ui.CountStyle.Render(counts)
 			}
 			result += line + "\n"
-			if script.Description != "" {
-				result += ui.DimStyle.Render(fmt.Sprintf("      %s", script.Description)) + "\n"
+			if details := scriptDetails(script); details != "" {
+				result += ui.DimStyle.Render(fmt.Sprintf("      %s", details)) + "\n"
 			}
 		}
 	}
 	return result
 }
 
+// scriptDetails returns the description line shown under a script, including its header tags
+func scriptDetails(script Script) string {
+	details := script.Description
+	if len(script.Tags) > 0 {
+		tags := "[" + strings.Join(script.Tags, ", ") + "]"
+		if details != "" {
+			details += "  " + tags
+		} else {
+			details = tags
+		}
+	}
+	if len(script.Unmet) > 0 {
+		details += "  ⚠ falta: " + strings.Join(script.Unmet, ", ")
+	}
+	if len(script.Overrides) > 0 {
+		labels := make([]string, 0, len(script.Overrides))
+		for _, origin := range script.Overrides {
+			labels = append(labels, OriginLabel(origin))
+		}
+		details += "  (reemplaza a: " + strings.Join(labels, ", ") + ")"
+	}
+	return strings.TrimSpace(details)
+}
+
+func (m Model) renderParamFormView() string {
+	breadcrumb := ui.RenderBreadcrumb([]string{"Inicio", m.currentCategory.Name, m.currentScript.DisplayName()}, m.runDir)
+	return breadcrumb + m.paramForm.View()
+}
+
+func (m Model) renderPreflightView() string {
+	breadcrumb := ui.RenderBreadcrumb([]string{"Inicio", m.currentCategory.Name, m.currentScript.DisplayName()}, m.runDir)
+	return breadcrumb + renderPreflight(m.currentScript, m.preflightChecks)
+}
+
+func (m Model) renderConfirmView() string {
+	breadcrumb := ui.RenderBreadcrumb([]string{"Inicio", m.currentCategory.Name, m.currentScript.DisplayName()}, m.runDir)
+	return breadcrumb + m.confirmGate.View()
+}
+
+func (m Model) renderFinderView() string {
+	breadcrumb := ui.RenderBreadcrumb([]string{"Inicio", "Buscar"}, m.runDir)
+	return breadcrumb + m.finder.View(m.height)
+}
+
 func (m Model) renderExecutingView() string {
 	content := "\n"
-	content += ui.TitleStyle.Render("⚡ Ejecutando: "+m.currentScript.Name) + "\n\n"
-	content += ui.DimStyle.Render("El script se está ejecutando...") + "\n"
-	
+	content += ui.TitleStyle.Render("⚡ Ejecutando: "+m.currentScript.DisplayName()) + "\n\n"
+
+	run := m.activeRun
+	if run == nil {
+		content += ui.DimStyle.Render("El script se está ejecutando...") + "\n"
+		return content
+	}
+
+	status := fmt.Sprintf("Tiempo: %s", formatDuration(run.Elapsed()))
+	if run.timeout > 0 {
+		status += fmt.Sprintf(" / límite %s", run.timeout)
+	}
+	switch run.StopReason() {
+	case RunCancelled:
+		status += "  " + ui.ErrorStyle.Render("cancelando...")
+	case RunTimedOut:
+		status += "  " + ui.ErrorStyle.Render("tiempo agotado, deteniendo...")
+	}
+	content += ui.DimStyle.Render(status) + "\n"
+	content += ui.DimStyle.Render("─────────────────────────────────────────────────────────────") + "\n"
+
+	visible := m.height - 9
+	if visible < 5 {
+		visible = 5
+	}
+	for _, line := range run.output.Tail(visible) {
+		content += line + "\x1b[0m\n"
+	}
+
+	content += "\n" + ui.DimStyle.Render("x/ctrl+c: cancelar (SIGINT; pulsa de nuevo para forzar)")
 	return content
 }
 
@@ -501,83 +973,22 @@ func (m Model) renderResultView() string {
 	content := breadcrumb
 	
 	// Status header with exit code
-	if m.executionResult == 0 {
+	switch {
+	case m.executionStatus == RunCancelled:
+		content += ui.ErrorStyle.Render(fmt.Sprintf("⊘ Ejecución cancelada tras %s (exit code: %d)", formatDuration(m.executionTime), m.executionResult)) + "\n"
+	case m.executionStatus == RunTimedOut:
+		content += ui.ErrorStyle.Render(fmt.Sprintf("⏱ Tiempo límite agotado tras %s (exit code: %d)", formatDuration(m.executionTime), m.executionResult)) + "\n"
+	case m.executionResult == 0:
 		content += ui.SuccessStyle.Render(fmt.Sprintf("✓ Script completado exitosamente (exit code: %d)", m.executionResult)) + "\n"
-	} else {
+	default:
 		content += ui.ErrorStyle.Render(fmt.Sprintf("✗ Script falló (exit code: %d)", m.executionResult)) + "\n"
 	}
 	
 	content += ui.DimStyle.Render("─────────────────────────────────────────────────────────────") + "\n"
 	
-	// Show scrollable output
-	if m.executionOutput != "" {
-		content += ui.NormalStyle.Render("Salida del script:") + "\n\n"
-		
-		// Use a fixed conservative width that works for most terminals
-		maxWidth := 80  // Standard terminal width
-		
-		// Split output into lines
-		rawLines := strings.Split(m.executionOutput, "\n")
-		wrappedLines := []string{}
-		
-		for _, line := range rawLines {
-			// Handle empty lines
-			if strings.TrimSpace(line) == "" {
-				wrappedLines = append(wrappedLines, "")
-				continue
-			}
-			
-			// Convert to runes for proper character counting
-			runes := []rune(line)
-			
-			// Keep wrapping until we process the entire line
-			for len(runes) > 0 {
-				if len(runes) <= maxWidth {
-					// This is the last chunk
-					wrappedLines = append(wrappedLines, string(runes))
-					break
-				} else {
-					// Cut at maxWidth and continue
-					wrappedLines = append(wrappedLines, string(runes[:maxWidth]))
-					runes = runes[maxWidth:]
-				}
-			}
-		}
-		
-		visibleHeight := m.height - 12  // Reserve space for header, footer, etc.
-		if visibleHeight < 5 {
-			visibleHeight = 5
-		}
-		
-		// Ensure scroll doesn't go beyond content
-		maxScroll := len(wrappedLines) - visibleHeight
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		if m.outputScroll > maxScroll {
-			m.outputScroll = maxScroll
-		}
-		
-		start := m.outputScroll
-		end := start + visibleHeight
-		if end > len(wrappedLines) {
-			end = len(wrappedLines)
-		}
-		
-		for i := start; i < end; i++ {
-			content += wrappedLines[i] + "\n"
-		}
-		
-		// Show scroll indicator if there's more content
-		if len(wrappedLines) > visibleHeight {
-			scrollInfo := fmt.Sprintf("[Líneas %d-%d de %d]", start+1, end, len(wrappedLines))
-			content += "\n" + ui.DimStyle.Render(scrollInfo) + "\n"
-		}
-	} else {
-		content += ui.DimStyle.Render("(Sin salida)") + "\n"
-	}
-	
-	content += "\n" + ui.DimStyle.Render("↑↓/j/k/scroll: desplazar  enter/./0/esc: volver  q: salir")
+	content += renderDiagnoses(m.diagnoses, m.diagnosisErrs)
+	content += m.resultViewer.View()
+	content += "\n" + ui.DimStyle.Render("↑↓/pgup/pgdn/inicio/fin: desplazar  /: buscar  n/N: siguiente  w: ajuste  s: guardar  c: copiar  enter/esc: volver  q: salir")
 	
 	return content
 }
@@ -602,7 +1013,7 @@ type scriptItem struct {
 }
 
 func (i scriptItem) FilterValue() string { return i.script.Name }
-func (i scriptItem) Title() string       { return i.script.Name }
+func (i scriptItem) Title() string       { return i.script.DisplayName() }
 func (i scriptItem) Description() string { return i.script.Description }
 
 func (m Model) createCategoryList() list.Model {
@@ -684,6 +1095,7 @@ func decorateHeaderWithVersion(header, version string) string {
 
 type categoriesLoadedMsg struct {
 	categories []Category
+	favorites  []string
 }
 
 type scriptsLoadedMsg struct {
@@ -693,19 +1105,26 @@ type scriptsLoadedMsg struct {
 type scriptExecutedMsg struct {
 	exitCode int
 	output   string  // Combined stdout + stderr
+	entry    HistoryEntry
+}
+
+type historyLoadedMsg struct {
+	entries []HistoryEntry
 }
 
 type errorMsg struct {
 	err error
 }
 
-func loadCategories(rootDir string) tea.Cmd {
+func loadCategories(roots []ScriptRoot, favoritesPath string) tea.Cmd {
 	return func() tea.Msg {
-		cats, err := ScanCategories(rootDir)
+		cats, err := ScanCategories(roots)
 		if err != nil {
 			return errorMsg{err}
 		}
-		return categoriesLoadedMsg{categories: cats}
+		// A broken favorites file should not hide the categories
+		favorites, _ := LoadFavorites(favoritesPath)
+		return categoriesLoadedMsg{categories: cats, favorites: favorites}
 	}
 }
 
@@ -719,13 +1138,62 @@ func loadScripts(categoryPath string) tea.Cmd {
 	}
 }
 
-func executeScript(script Script, workingDir string) tea.Cmd {
-	return tea.ExecProcess(getScriptCommand(script, workingDir), func(err error) tea.Msg {
-		exitCode, output := ExecuteScript(script, workingDir)
-		return scriptExecutedMsg{exitCode: exitCode, output: output}
+// executeScript starts a script. In terminal mode the script takes over the
+// terminal through tea.Exec; in capture mode it runs in the background while
+// ExecutingView shows its output and accepts x/ctrl+c to cancel it.
+func (m *Model) executeScript(script Script, workingDir string, opts RunOptions) tea.Cmd {
+	run, err := newScriptRun(script, workingDir, opts)
+	if err != nil {
+		return func() tea.Msg {
+			now := time.Now()
+			entry := newHistoryEntry(script, workingDir, opts, now, now, 1, err.Error(), SourceTUI)
+			entry.Status = RunFailed
+			return scriptExecutedMsg{exitCode: 1, output: err.Error(), entry: entry}
+		}
+	}
+	m.activeRun = run
+
+	finished := func(err error) tea.Msg {
+		exitCode, output := run.Result(err)
+		return scriptExecutedMsg{exitCode: exitCode, output: output, entry: run.HistoryEntry(exitCode, output, SourceTUI)}
+	}
+
+	if runMode(script) == config.RunModeCapture {
+		run.SetStdout(io.Discard)
+		run.SetStderr(io.Discard)
+		return tea.Batch(func() tea.Msg { return finished(run.Run()) }, execTick())
+	}
+	return tea.Exec(run, finished)
+}
+
+type execTickMsg time.Time
+
+// execTick refreshes ExecutingView while a captured script runs
+func execTick() tea.Cmd {
+	return tea.Tick(250*time.Millisecond, func(t time.Time) tea.Msg {
+		return execTickMsg(t)
 	})
 }
 
+func saveHistory(historyPath string, entry HistoryEntry) tea.Cmd {
+	return func() tea.Msg {
+		if err := AppendHistory(historyPath, entry); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
+}
+
+func loadHistory(historyPath string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := LoadHistory(historyPath)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return historyLoadedMsg{entries: entries}
+	}
+}
+
 // ListAllScripts prints all scripts organized by category
 func ListAllScripts() {
 	// Get root directory - try multiple strategies
@@ -745,14 +1213,17 @@ func ListAllScripts() {
 		rootDir = filepath.Dir(realPath)
 	}
 
-	categories, err := ScanCategories(rootDir)
+	launchDir, _ := os.Getwd()
+	categories, err := ScanCategories(ResolveScriptRoots(rootDir, launchDir))
 	if err != nil {
 		fmt.Printf("Error scanning categories: %v\n", err)
 		return
 	}
 
 	staticDir := utils.GetStaticPath(rootDir)
-	fmt.Println(ui.LoadASCIIArt(staticDir))
+	if header := loadHeader(staticDir); header != "" {
+		fmt.Println(header)
+	}
 	fmt.Println(ui.RenderBreadcrumb([]string{"Inicio", "Lista completa"}, rootDir))
 	
 	totalScripts := 0
@@ -761,9 +1232,13 @@ func ListAllScripts() {
 		fmt.Println(ui.DimStyle.Render(cat.Description))
 		fmt.Println()
 		
-		scripts, _ := ScanScripts(cat.Path)
+		scripts, _ := ScanMergedScripts(cat.Sources)
 		for _, script := range scripts {
-			fmt.Printf("  • %s\n", script.Name)
+			if badge := originBadge(script.Origin); badge != "" {
+				fmt.Printf("  • %s %s\n", script.Name, ui.DimStyle.Render(badge))
+			} else {
+				fmt.Printf("  • %s\n", script.Name)
+			}
 			if script.Description != "" {
 				fmt.Printf("    %s\n", ui.DimStyle.Render("── "+script.Description))
 			}
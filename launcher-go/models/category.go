@@ -15,32 +15,56 @@ import (
 // Category represents a script category
 type Category struct {
 	Name        string
-	Path        string
+	Path        string       // Folder in the highest-precedence root
+	Sources     []ScriptRoot // Same-named folders of every root, highest precedence first
 	Icon        string
 	Description string
 	DirCount    int
 	ScriptCount int
+	Favorites   bool // Favoritos pseudo-category (no Path)
 }
 
-// ScanCategories scans the scripts directory and returns all categories
-func ScanCategories(rootDir string) ([]Category, error) {
-	scriptsPath := utils.GetScriptsPath(rootDir)
-	entries, err := os.ReadDir(scriptsPath)
-	if err != nil {
-		return nil, err
-	}
-
-	var categories []Category
-	for _, entry := range entries {
-		if !entry.IsDir() {
+// ScanCategories scans every script root (lowest precedence first, see
+// ResolveScriptRoots) and merges same-named categories
+func ScanCategories(roots []ScriptRoot) ([]Category, error) {
+	sources := map[string][]ScriptRoot{}
+	var names []string
+	readable := false
+	var firstErr error
+
+	for i := len(roots) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(roots[i].Path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
 			continue
 		}
-		if strings.EqualFold(entry.Name(), "lib") {
-			continue
+		readable = true
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if isHiddenFolder(entry.Name()) {
+				continue
+			}
+			if _, seen := sources[entry.Name()]; !seen {
+				names = append(names, entry.Name())
+			}
+			sources[entry.Name()] = append(sources[entry.Name()], ScriptRoot{
+				Origin: roots[i].Origin,
+				Path:   filepath.Join(roots[i].Path, entry.Name()),
+			})
 		}
+	}
+	if !readable {
+		return nil, firstErr
+	}
 
-		categoryPath := filepath.Join(scriptsPath, entry.Name())
-		items, scanErr := ScanScripts(categoryPath)
+	var categories []Category
+	for _, name := range names {
+		items, scanErr := ScanMergedScripts(sources[name])
 		if scanErr != nil {
 			continue
 		}
@@ -58,11 +82,13 @@ func ScanCategories(rootDir string) ([]Category, error) {
 			}
 		}
 
+		icon, description := mergedFolderMeta(sources[name], name)
 		categories = append(categories, Category{
-			Name:        entry.Name(),
-			Path:        categoryPath,
-			Icon:        folderIconFromREADME(categoryPath, entry.Name()),
-			Description: folderDescriptionFromREADME(categoryPath, entry.Name()),
+			Name:        name,
+			Path:        sources[name][0].Path,
+			Sources:     sources[name],
+			Icon:        icon,
+			Description: description,
 			DirCount:    dirCount,
 			ScriptCount: scriptCount,
 		})
@@ -76,6 +102,31 @@ func ScanCategories(rootDir string) ([]Category, error) {
 	return categories, nil
 }
 
+// mergedFolderMeta returns the icon and description of a folder present in
+// several roots, from the first README found (highest precedence first)
+func mergedFolderMeta(sources []ScriptRoot, name string) (string, string) {
+	icon, desc := "", ""
+	for _, source := range sources {
+		readmeIcon, readmeDesc, ok := readmeFolderMeta(source.Path)
+		if !ok {
+			continue
+		}
+		if icon == "" {
+			icon = readmeIcon
+		}
+		if strings.TrimSpace(desc) == "" {
+			desc = readmeDesc
+		}
+	}
+	if icon == "" {
+		icon = utils.CategoryIcon(name)
+	}
+	if strings.TrimSpace(desc) == "" {
+		desc = utils.CategoryDescription(name)
+	}
+	return icon, desc
+}
+
 func folderDescriptionFromREADME(folderPath, fallback string) string {
 	_, desc, ok := readmeFolderMeta(folderPath)
 	if !ok || strings.TrimSpace(desc) == "" {
@@ -21,7 +21,7 @@ type CommandMode struct {
 	viewport viewport.Model
 }
 
-var commandSuggestions = []string{"help", "h", "list", "ls", "pwd", "cd", "search", "clear", "exit", "quit", "q"}
+var commandSuggestions = []string{"help", "h", "list", "ls", "pwd", "cd", "search", "history", "fav", "roots", "interpreters", "env", "jobs", "clear", "exit", "quit", "q"}
 
 // NewCommandMode creates a new command mode
 func NewCommandMode() CommandMode {
@@ -251,7 +251,16 @@ func (c *CommandMode) HandleCommand(cmd string, m *Model) tea.Cmd {
 			"  pwd              - Mostrar directorio actual de ejecución\n" +
 			"  cd <ruta>        - Cambiar directorio de ejecución\n" +
 			"  ls [ruta]        - Listar archivos y carpetas\n" +
-			"  search <texto>   - Buscar scripts\n" +
+			"  search [texto]   - Buscar en todo el árbol de scripts (tecla /)\n" +
+			"  history          - Ver historial de ejecuciones (tecla H)\n" +
+			"  fav [N]          - Marcar/desmarcar favorito (seleccionado o item N, tecla f)\n" +
+			"  roots            - Ver las carpetas de scripts combinadas y su prioridad\n" +
+			"  interpreters     - Ver los intérpretes y si están instalados\n" +
+			"  env [N]          - Ver el entorno del script (.env, DEVLAUNCHER_*, sesión)\n" +
+			"  env set K=V      - Definir una variable para las próximas ejecuciones\n" +
+			"  env unset K      - Quitar una variable de sesión\n" +
+			"  env edit         - Editar el .env de la carpeta actual\n" +
+			"  jobs             - Ver los scripts en segundo plano (tecla J)\n" +
 			"  clear            - Limpiar pantalla\n" +
 			"  exit, quit, q    - Salir del launcher\n" +
 			"  :1, :2, :3...    - Ir directamente al item N"
@@ -341,34 +350,73 @@ func (c *CommandMode) HandleCommand(cmd string, m *Model) tea.Cmd {
 		}
 
 	case "search":
-		if len(parts) < 2 {
-			c.output = ui.ErrorStyle.Render("Uso: search <texto>")
-		} else {
-			query := strings.ToLower(strings.Join(parts[1:], " "))
-			c.output = fmt.Sprintf("Buscando: %s\n", query)
-			
-			if m.state == CategoryView {
-				for i, cat := range m.categories {
-					if strings.Contains(strings.ToLower(cat.Name), query) ||
-						strings.Contains(strings.ToLower(cat.Description), query) {
-						c.output += fmt.Sprintf("  [%d] %s %s\n", i+1, cat.Icon, cat.Name)
-					}
-				}
-			} else if m.state == ScriptView {
-				for i, script := range m.scripts {
-					if strings.Contains(strings.ToLower(script.Name), query) ||
-						strings.Contains(strings.ToLower(script.Description), query) {
-						c.output += fmt.Sprintf("  [%d] %s\n", i+1, script.Name)
-					}
-				}
+		// Open the global finder with the query prefilled
+		c.active = false
+		c.output = ""
+		return m.openFinder(strings.Join(parts[1:], " "))
+
+	case "fav":
+		if m.state != ScriptView || len(m.scripts) == 0 {
+			c.output = ui.ErrorStyle.Render("fav solo funciona dentro de una carpeta de scripts")
+			break
+		}
+		index := m.scriptList.Index()
+		if len(parts) > 1 {
+			var num int
+			if _, err := fmt.Sscanf(parts[1], "%d", &num); err != nil {
+				c.output = ui.ErrorStyle.Render("Uso: fav [N]")
+				break
 			}
+			index = num - 1
+		}
+		if index < 0 || index >= len(m.scripts) {
+			c.output = ui.ErrorStyle.Render(fmt.Sprintf("Item %d no existe", index+1))
+			break
 		}
+		item := m.scripts[index]
+		cmd, starred := m.toggleFavorite(item)
+		if starred {
+			c.output = ui.SuccessStyle.Render("★ Añadido a favoritos: ") + item.DisplayName()
+		} else {
+			c.output = ui.SuccessStyle.Render("☆ Quitado de favoritos: ") + item.DisplayName()
+		}
+		c.syncViewport()
+		return cmd
+
+	case "roots":
+		c.output = "Carpetas de scripts (las últimas tienen prioridad):\n"
+		for i, root := range m.roots {
+			c.output += fmt.Sprintf("  [%d] %-16s %s\n", i+1, OriginLabel(root.Origin), root.Path)
+		}
+
+	case "env":
+		out, cmd := m.envCommand(parts[1:])
+		c.output = out
+		if cmd != nil {
+			c.active = false
+			return cmd
+		}
+
+	case "interpreters":
+		c.output = "Intérpretes (✓ disponible en el PATH):\n" + describeInterpreters()
+
+	case "history":
+		c.active = false
+		if m.state != HistoryView {
+			m.historyReturn = m.state
+		}
+		return m.openHistory()
 
 	case "clear":
 		c.output = ""
 
+	case "jobs":
+		c.active = false
+		return m.openJobs()
+
 	case "exit", "quit", "q":
-		return tea.Quit
+		c.active = false
+		return m.quit()
 
 	default:
 		// Check for :N syntax (go to item N)
@@ -379,15 +427,11 @@ func (c *CommandMode) HandleCommand(cmd string, m *Model) tea.Cmd {
 			num-- // Convert to 0-based index
 			
 			if m.state == CategoryView && num >= 0 && num < len(m.categories) {
-				m.currentCategory = m.categories[num]
-				m.state = ScriptView
 				c.active = false
-				return loadScripts(m.currentCategory.Path)
+				return m.openCategory(m.categories[num])
 			} else if m.state == ScriptView && num >= 0 && num < len(m.scripts) {
-				m.currentScript = m.scripts[num]
-				m.state = ExecutingView
 				c.active = false
-				return executeScript(m.currentScript, m.runDir)
+				return m.openItem(m.scripts[num])
 			} else {
 				c.output = ui.ErrorStyle.Render(fmt.Sprintf("Item %d no existe", num+1))
 			}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/ui"
+	"github.com/lucas/launcher/utils"
+)
+
+// LoadConfig reads the configuration file and makes it the current one.
+// Invalid values fall back to their default and are returned as errors.
+func LoadConfig() []error {
+	cfg, errs := config.Load(utils.GetConfigPath())
+	config.SetCurrent(cfg)
+	return errs
+}
+
+// loadHeader returns the ASCII header selected by the header setting
+func loadHeader(staticDir string) string {
+	switch header := config.Current().Header; header {
+	case config.HeaderNone:
+		return ""
+	case config.HeaderRandom, "":
+		return ui.LoadASCIIArt(staticDir)
+	default:
+		return ui.LoadASCIIArtFile(staticDir, header)
+	}
+}
+
+// ConfigCLI implements "launcher config [list | get KEY | set KEY VALUE | unset KEY | path]"
+func ConfigCLI(args []string) int {
+	path := utils.GetConfigPath()
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	switch action {
+	case "path":
+		fmt.Println(path)
+		return 0
+
+	case "list", "ls":
+		cfg, errs := config.Load(path)
+		for _, key := range config.Keys() {
+			value, _ := cfg.Get(key)
+			fmt.Printf("%-16s = %-20s %s\n", key, value, ui.DimStyle.Render("# "+config.Help(key)))
+		}
+		return printConfigErrors(errs)
+
+	case "get":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Uso: launcher config get <clave>")
+			return 2
+		}
+		cfg, errs := config.Load(path)
+		value, err := cfg.Get(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		fmt.Println(value)
+		return printConfigErrors(errs)
+
+	case "set", "unset":
+		if (action == "set" && len(args) != 2) || (action == "unset" && len(args) != 1) {
+			fmt.Fprintln(os.Stderr, "Uso: launcher config set <clave> <valor> | unset <clave>")
+			return 2
+		}
+		cfg, errs := config.Load(path)
+		if len(errs) > 0 {
+			// Do not overwrite a file we could not read correctly
+			printConfigErrors(errs)
+			fmt.Fprintln(os.Stderr, "Corrige el archivo antes de modificarlo:", path)
+			return 1
+		}
+
+		var err error
+		if action == "set" {
+			err = cfg.Set(args[0], args[1])
+		} else {
+			err = cfg.Unset(args[0])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		if err := config.Save(path, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "Error guardando configuración:", err)
+			return 1
+		}
+		value, _ := cfg.Get(args[0])
+		fmt.Printf("%s = %s\n", args[0], value)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Acción desconocida: %s\n", action)
+	fmt.Fprintln(os.Stderr, "Uso: launcher config [list | get <clave> | set <clave> <valor> | unset <clave> | path]")
+	return 2
+}
+
+func printConfigErrors(errs []error) int {
+	if len(errs) == 0 {
+		return 0
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ Configuración inválida: ")+err.Error())
+	}
+	return 1
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/ui"
+)
+
+// ConfirmGate is the screen shown before running a script marked @confirm
+// (answer s/n) or dangerous (type its name). It lists exactly what is about
+// to run: script path, working directory, arguments and parameters.
+type ConfirmGate struct {
+	script Script
+	runDir string
+	opts   RunOptions
+	typed  bool
+	input  textinput.Model
+	err    string
+}
+
+// NewConfirmGate prepares the confirmation of one run. typed asks for the
+// script name instead of a yes/no answer.
+func NewConfirmGate(script Script, runDir string, opts RunOptions, typed bool) ConfirmGate {
+	ti := textinput.New()
+	ti.CharLimit = 128
+	ti.Width = 40
+	ti.Prompt = "› "
+	if typed {
+		ti.Placeholder = confirmWord(script)
+		ti.Focus()
+	}
+	return ConfirmGate{script: script, runDir: runDir, opts: opts, typed: typed, input: ti}
+}
+
+// confirmWord is what the user types to confirm a dangerous script
+func confirmWord(script Script) string {
+	return strings.TrimSuffix(script.Name, script.Extension)
+}
+
+// Update handles a key. It reports whether the run was accepted or cancelled.
+func (g *ConfirmGate) Update(msg tea.KeyMsg) (accepted, cancelled bool, cmd tea.Cmd) {
+	if msg.String() == "esc" {
+		return false, true, nil
+	}
+
+	if !g.typed {
+		switch strings.ToLower(msg.String()) {
+		case "s", "y":
+			return true, false, nil
+		case "n", "enter", ".", "0":
+			return false, true, nil
+		}
+		return false, false, nil
+	}
+
+	if msg.String() == "enter" {
+		if strings.TrimSpace(g.input.Value()) == confirmWord(g.script) {
+			return true, false, nil
+		}
+		g.err = fmt.Sprintf("Escribe %q exactamente para ejecutarlo", confirmWord(g.script))
+		return false, false, nil
+	}
+	g.err = ""
+	g.input, cmd = g.input.Update(msg)
+	return false, false, cmd
+}
+
+// View renders the run summary and the prompt
+func (g ConfirmGate) View() string {
+	content := "\n"
+	if g.typed {
+		content += ui.ErrorStyle.Render("⚠ Script peligroso: "+g.script.DisplayName()) + "\n\n"
+	} else {
+		content += ui.WarningStyle.Render("¿Ejecutar "+g.script.DisplayName()+"?") + "\n\n"
+	}
+
+	content += g.Summary()
+
+	if !g.typed {
+		content += "\n" + ui.DimStyle.Render("s: ejecutar  n/esc: cancelar")
+		return content
+	}
+
+	content += "\n" + fmt.Sprintf("Escribe %s para confirmar:", ui.TitleStyle.Render(confirmWord(g.script))) + "\n"
+	content += g.input.View() + "\n"
+	if g.err != "" {
+		content += ui.ErrorStyle.Render("✗ "+g.err) + "\n"
+	}
+	content += "\n" + ui.DimStyle.Render("enter: ejecutar  esc: cancelar")
+	return content
+}
+
+// Summary lists what is about to run
+func (g ConfirmGate) Summary() string {
+	args := append(append([]string(nil), g.script.Args...), g.opts.Args...)
+	argsText := ui.DimStyle.Render("(ninguno)")
+	if len(args) > 0 {
+		argsText = quoteArgs(args)
+	}
+	summary := fmt.Sprintf("  %-12s %s\n", "Script:", g.script.Path)
+	summary += fmt.Sprintf("  %-12s %s\n", "Directorio:", resolveWorkingDir(g.script, g.runDir))
+	summary += fmt.Sprintf("  %-12s %s\n", "Argumentos:", argsText)
+	if len(g.opts.Env) > 0 {
+		summary += fmt.Sprintf("  %-12s %s\n", "Variables:", strings.Join(g.opts.Env, " "))
+	}
+	if g.script.Description != "" {
+		summary += "\n  " + ui.DimStyle.Render(g.script.Description) + "\n"
+	}
+	return summary
+}
+
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isDangerous reports whether a script needs a typed confirmation: it is
+// marked @dangerous or matches dangerous_scripts in the config, by name
+// (extension optional) or by its category/path inside the tree
+func isDangerous(script Script, logical string) bool {
+	if script.Dangerous {
+		return true
+	}
+	name := confirmWord(script)
+	logical = strings.TrimSuffix(logical, script.Extension)
+	for _, pattern := range config.Current().DangerousScripts {
+		pattern = strings.TrimSuffix(pattern, filepath.Ext(pattern))
+		for _, candidate := range []string{name, logical} {
+			if candidate == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scriptLogicalPath returns category/path/name of a script inside the merged
+// tree, or "" when it is not under any category
+func scriptLogicalPath(categories []Category, script Script) string {
+	for _, cat := range categories {
+		if cat.Favorites {
+			continue
+		}
+		if rel, ok := folderRel(cat, filepath.Dir(script.Path)); ok {
+			if rel == "." {
+				return cat.Name + "/" + script.Name
+			}
+			return cat.Name + "/" + rel + "/" + script.Name
+		}
+	}
+	return ""
+}
+
+// openConfirm shows the confirmation gate for a run when the script asks
+// for one. It returns false when the script can run right away.
+func (m *Model) openConfirm(script Script, runDir string, opts RunOptions) bool {
+	typed := isDangerous(script, scriptLogicalPath(m.categories, script))
+	if !typed && !script.Confirm {
+		return false
+	}
+	m.confirmGate = NewConfirmGate(script, runDir, opts, typed)
+	m.confirmReturn = m.state
+	if m.state == ParamFormView {
+		m.confirmReturn = ScriptView
+	}
+	m.currentScript = script
+	m.state = ConfirmView
+	return true
+}
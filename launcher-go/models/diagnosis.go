@@ -0,0 +1,360 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucas/launcher/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// diagnosisFileName holds the extra diagnosis rules of a scripts root, next
+// to its category folders
+const diagnosisFileName = "diagnostics.yaml"
+
+// DiagnosisRule recognises a known error in the output of a failed run and
+// suggests how to fix it. Rules files look like:
+//
+//	rules:
+//	  - id: webkit
+//	    title: Falta webkit2gtk
+//	    patterns: ['Package (webkit2gtk-[\d.]+) was not found']
+//	    advice: Wails necesita las cabeceras de $1
+//	    commands: ['sudo apt install lib$1-dev']
+//	    os: [debian]
+//
+// $1..$9 in advice and commands are replaced by the groups of the matching
+// pattern; commands that use a group the pattern did not capture are left out.
+type DiagnosisRule struct {
+	ID       string   `yaml:"id"` // A rule with the id of a built-in one replaces it
+	Title    string   `yaml:"title"`
+	Patterns []string `yaml:"patterns"` // Regular expressions tried on each output line
+	Advice   string   `yaml:"advice"`
+	Commands []string `yaml:"commands"`
+	OS       []string `yaml:"os"` // Same syntax as @requires-os
+	Disabled bool     `yaml:"disabled"`
+
+	compiled []*regexp.Regexp
+}
+
+// Diagnosis is a rule that matched the output of a run
+type Diagnosis struct {
+	Rule     *DiagnosisRule
+	Line     int    // 1-based output line of the first match
+	Text     string // That line
+	Advice   string
+	Commands []string
+}
+
+type diagnosisFile struct {
+	Rules []DiagnosisRule `yaml:"rules"`
+}
+
+// builtinDiagnosisRules are the errors recognised in any script, the Go side
+// of get_error_solution in common.sh
+func builtinDiagnosisRules() []DiagnosisRule {
+	windows := runtime.GOOS == "windows"
+	pick := func(unix, win []string) []string {
+		if windows {
+			return win
+		}
+		return unix
+	}
+
+	return []DiagnosisRule{
+		{
+			ID:    "command_not_found",
+			Title: "Comando no encontrado",
+			Patterns: []string{
+				// zsh prints "zsh: command not found: x": try it before the bash form.
+				`command not found: ([\w.+-]+)`,
+				`([\w.+-]+): command not found`,
+				`([\w.+-]+): (?:orden no encontrada|no se encontró la orden)`,
+				`^(?:\S+: )?\d+: ([\w.+-]+): not found`,
+				`'([\w.+-]+)' (?:is not recognized as|no se reconoce como)`,
+				`exec: "([\w.+-]+)": executable file not found`,
+			},
+			Advice: "El comando no está instalado o no está en el PATH. Búscalo entre los instaladores con / o revisa el PATH",
+			Commands: pick(
+				[]string{"command -v $1", `echo "$PATH"`},
+				[]string{"Get-Command $1", "$env:Path -split ';'"},
+			),
+		},
+		{
+			ID:    "permission_denied",
+			Title: "Permiso denegado",
+			Patterns: []string{
+				`([^\s:'"]*[/\\][^\s:'"]+)'?: [Pp]ermission denied`,
+				`(?i)permission denied`,
+				`(?i)operation not permitted`,
+				`EACCES|EPERM`,
+				`(?i)access (?:to the path .* )?is denied`,
+				`(?i)(?:acceso|permiso) denegado`,
+			},
+			Advice: "El usuario actual no puede leer, escribir o ejecutar un archivo. Revisa el propietario y los permisos, o ejecuta como administrador si el script lo requiere",
+			Commands: pick(
+				[]string{"ls -l $1", "chmod +x $1", "sudo chown $(whoami): $1"},
+				[]string{"Get-Acl $1 | Format-List"},
+			),
+		},
+		{
+			ID:    "port_in_use",
+			Title: "Puerto en uso",
+			Patterns: []string{
+				`:(\d{2,5}): bind: address already in use`,
+				`EADDRINUSE.*:(\d{2,5})\b`,
+				`(?i)port (\d{2,5}) is (?:already )?in use`,
+				`(?i)address already in use`,
+				`(?i)only one usage of each socket address`,
+				`(?i)la dirección ya se está usando`,
+			},
+			Advice: "Otro proceso ya escucha en ese puerto. Detenlo o configura un puerto distinto",
+			Commands: pick(
+				[]string{"lsof -i :$1", "ss -ltnp 'sport = :$1'"},
+				[]string{"Get-NetTCPConnection -LocalPort $1 | Select-Object OwningProcess", "netstat -ano | findstr :$1"},
+			),
+		},
+		{
+			ID:    "network_unreachable",
+			Title: "Sin conexión de red",
+			Patterns: []string{
+				`(?i)could not resolve host:? '?([\w.-]+)`,
+				`(?i)lookup ([\w.-]+)(?: on [\d.:]+)?: no such host`,
+				`(?i)getaddrinfo (?:ENOTFOUND|EAI_AGAIN) ([\w.-]+)`,
+				`(?i)network is unreachable`,
+				`(?i)temporary failure in name resolution`,
+				`(?i)name or service not known`,
+				`(?i)connection timed out|ETIMEDOUT`,
+				`(?i)unable to connect to the remote server`,
+				`(?i)no se (?:puede|pudo) resolver`,
+			},
+			Advice: "No se pudo conectar con el servidor. Comprueba la conexión a Internet, el DNS y el proxy",
+			Commands: pick(
+				[]string{"ping -c 3 $1", "ping -c 3 1.1.1.1", "env | grep -i proxy"},
+				[]string{"Test-NetConnection $1", "ping -n 3 1.1.1.1", "netsh winhttp show proxy"},
+			),
+		},
+		{
+			ID:    "disk_full",
+			Title: "Disco lleno",
+			Patterns: []string{
+				`(?i)no space left on device|ENOSPC`,
+				`(?i)not enough space on the disk`,
+				`(?i)no queda espacio en el dispositivo`,
+				`(?i)espacio en disco insuficiente`,
+			},
+			Advice: "No queda espacio libre. Libera espacio (cachés, imágenes de Docker, paquetes antiguos) y repite",
+			Commands: pick(
+				[]string{"df -h", "du -sh ~/.cache/* | sort -h | tail", "docker system df"},
+				[]string{"Get-PSDrive -PSProvider FileSystem", "cleanmgr"},
+			),
+		},
+		{
+			ID:    "apt_lock",
+			Title: "apt/dpkg bloqueado",
+			Patterns: []string{
+				`(?i)could not get lock (/\S*[^\s.])`,
+				`(?i)no se pudo bloquear (/\S*[^\s.])`,
+				`(?i)unable to acquire the dpkg frontend lock`,
+				`(?i)unable to lock the administration directory`,
+			},
+			Advice:   "Otro proceso de apt o dpkg (a menudo las actualizaciones automáticas) tiene el bloqueo. Espera a que termine; si no hay ninguno, repara dpkg",
+			Commands: []string{"ps aux | grep -E '[a]pt|[d]pkg'", "sudo lsof $1", "sudo dpkg --configure -a"},
+			OS:       []string{"linux"},
+		},
+	}
+}
+
+// LoadDiagnosisRules returns the rules of the diagnostics.yaml files of the
+// scripts roots (highest precedence first) followed by the built-in ones. A
+// rule replaces later ones with the same id. Files that cannot be read are
+// reported and skipped.
+func LoadDiagnosisRules(roots []ScriptRoot) ([]DiagnosisRule, []error) {
+	var rules []DiagnosisRule
+	var errs []error
+	seen := map[string]bool{}
+	add := func(rule DiagnosisRule) {
+		if seen[rule.ID] {
+			return
+		}
+		seen[rule.ID] = true
+		if !rule.Disabled {
+			rules = append(rules, rule)
+		}
+	}
+
+	for i := len(roots) - 1; i >= 0; i-- {
+		file := filepath.Join(roots[i].Path, diagnosisFileName)
+		fileRules, err := loadDiagnosisFile(file)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		for _, rule := range fileRules {
+			add(rule)
+		}
+	}
+	for _, rule := range builtinDiagnosisRules() {
+		if err := rule.compile(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(rule)
+	}
+	return rules, errs
+}
+
+// loadDiagnosisFile reads and validates one rules file
+func loadDiagnosisFile(file string) ([]DiagnosisRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var df diagnosisFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&df); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	ids := map[string]bool{}
+	for i := range df.Rules {
+		rule := &df.Rules[i]
+		if rule.ID == "" {
+			return nil, fmt.Errorf("%s: la regla %d no tiene id", file, i+1)
+		}
+		if ids[rule.ID] {
+			return nil, fmt.Errorf("%s: id de regla repetido: %s", file, rule.ID)
+		}
+		ids[rule.ID] = true
+		if rule.Disabled {
+			continue
+		}
+		if len(rule.Patterns) == 0 {
+			return nil, fmt.Errorf("%s: la regla %s no tiene patterns", file, rule.ID)
+		}
+		if rule.Title == "" {
+			rule.Title = rule.ID
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return df.Rules, nil
+}
+
+func (r *DiagnosisRule) compile() error {
+	r.compiled = r.compiled[:0]
+	for _, pattern := range r.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("regla %s: patrón %q no válido: %v", r.ID, pattern, err)
+		}
+		r.compiled = append(r.compiled, re)
+	}
+	return nil
+}
+
+// appliesHere reports whether the os list of the rule matches this system
+func (r *DiagnosisRule) appliesHere() bool {
+	if len(r.OS) == 0 {
+		return true
+	}
+	for _, spec := range r.OS {
+		if currentOS().Satisfies(spec) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnose checks the output lines (without colours) against the rules and
+// returns one diagnosis per matching rule, in rule order
+func Diagnose(rules []DiagnosisRule, lines []string) []Diagnosis {
+	var found []Diagnosis
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.appliesHere() {
+			continue
+		}
+		if d, ok := rule.match(lines); ok {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// match finds the first line matched by any pattern of the rule. Patterns
+// are tried in order on each line so the ones capturing details win.
+func (r *DiagnosisRule) match(lines []string) (Diagnosis, bool) {
+	for n, line := range lines {
+		for _, re := range r.compiled {
+			groups := re.FindStringSubmatch(line)
+			if groups == nil {
+				continue
+			}
+			d := Diagnosis{Rule: r, Line: n + 1, Text: strings.TrimSpace(line)}
+			d.Advice, _ = expandGroups(r.Advice, groups)
+			for _, command := range r.Commands {
+				if expanded, ok := expandGroups(command, groups); ok {
+					d.Commands = append(d.Commands, expanded)
+				}
+			}
+			return d, true
+		}
+	}
+	return Diagnosis{}, false
+}
+
+var groupReference = regexp.MustCompile(`\$([1-9])`)
+
+// expandGroups replaces $1..$9 with the captured groups. ok is false when
+// some referenced group is empty (replaced by nothing).
+func expandGroups(template string, groups []string) (string, bool) {
+	ok := true
+	expanded := groupReference.ReplaceAllStringFunc(template, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1:])
+		if n < len(groups) && groups[n] != "" {
+			return groups[n]
+		}
+		ok = false
+		return ""
+	})
+	return expanded, ok
+}
+
+// renderDiagnoses shows the matched rules above the output in ResultView
+func renderDiagnoses(diagnoses []Diagnosis, errs []error) string {
+	if len(diagnoses) == 0 && len(errs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if len(diagnoses) > 0 {
+		b.WriteString(ui.WarningStyle.Render("🩺 Diagnóstico") + "\n")
+	}
+	for _, d := range diagnoses {
+		b.WriteString("  " + ui.ErrorStyle.Render("✗ "+d.Rule.Title) + ui.DimStyle.Render(fmt.Sprintf(" (línea %d)", d.Line)) + "\n")
+		b.WriteString("    " + ui.DimStyle.Render(ansi.Truncate(d.Text, 100, "…")) + "\n")
+		if d.Advice != "" {
+			b.WriteString("    " + d.Advice + "\n")
+		}
+		for _, command := range d.Commands {
+			b.WriteString("    " + ui.SuccessStyle.Render("$ "+command) + "\n")
+		}
+	}
+	for _, err := range errs {
+		b.WriteString(ui.DimStyle.Render("  ⚠ "+err.Error()) + "\n")
+	}
+	return b.String()
+}
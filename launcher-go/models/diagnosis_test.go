@@ -0,0 +1,170 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func builtinRules(t *testing.T) []DiagnosisRule {
+	t.Helper()
+	rules, errs := LoadDiagnosisRules(nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return rules
+}
+
+func TestDiagnoseBuiltins(t *testing.T) {
+	rules := builtinRules(t)
+	tests := []struct {
+		line    string
+		rule    string
+		command string // First command on Linux and macOS, "" to skip
+	}{
+		{"./install.sh: line 4: wails: command not found", "command_not_found", "command -v wails"},
+		{"zsh: command not found: pnpm", "command_not_found", "command -v pnpm"},
+		{"/bin/sh: 1: node: not found", "command_not_found", "command -v node"},
+		{"bash: /usr/local/bin/tool: Permission denied", "permission_denied", "ls -l /usr/local/bin/tool"},
+		{"Error: EACCES, open 'x'", "permission_denied", ""},
+		{"listen tcp 127.0.0.1:8080: bind: address already in use", "port_in_use", "lsof -i :8080"},
+		{"curl: (6) Could not resolve host: example.com", "network_unreachable", "ping -c 3 example.com"},
+		{"write /tmp/x: no space left on device", "disk_full", "df -h"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			found := Diagnose(rules, []string{"preparando…", tt.line})
+			if len(found) == 0 || found[0].Rule.ID != tt.rule {
+				t.Fatalf("Diagnose(%q) = %+v, want %s", tt.line, found, tt.rule)
+			}
+			if found[0].Line != 2 || found[0].Text != tt.line {
+				t.Errorf("matched line %d %q", found[0].Line, found[0].Text)
+			}
+			if tt.command != "" && runtime.GOOS != "windows" && found[0].Commands[0] != tt.command {
+				t.Errorf("first command = %q, want %q", found[0].Commands[0], tt.command)
+			}
+		})
+	}
+
+	if found := Diagnose(rules, []string{"todo bien", "exit status 1"}); len(found) != 0 {
+		t.Errorf("Diagnose matched %+v", found)
+	}
+}
+
+func TestDiagnoseDropsCommandsWithoutGroups(t *testing.T) {
+	rules := builtinRules(t)
+	found := Diagnose(rules, []string{"Error: permission denied"})
+	if len(found) != 1 || found[0].Rule.ID != "permission_denied" {
+		t.Fatalf("Diagnose = %+v", found)
+	}
+	if runtime.GOOS != "windows" && len(found[0].Commands) != 0 {
+		t.Errorf("Commands = %q, want none without a captured path", found[0].Commands)
+	}
+}
+
+func TestExpandGroups(t *testing.T) {
+	groups := []string{"whole", "webkit2gtk-4.1", ""}
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"sudo apt install lib$1-dev", "sudo apt install libwebkit2gtk-4.1-dev", true},
+		{"sin grupos", "sin grupos", true},
+		{"$2 vacío", " vacío", false},
+		{"$5 fuera", " fuera", false},
+	}
+	for _, tt := range tests {
+		got, ok := expandGroups(tt.in, groups)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("expandGroups(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoadDiagnosisRules(t *testing.T) {
+	install, team := t.TempDir(), t.TempDir()
+	writeRules := func(dir, content string) {
+		if err := os.WriteFile(filepath.Join(dir, diagnosisFileName), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRules(install, `
+rules:
+  - id: webkit
+    title: Falta webkit2gtk
+    patterns: ['Package (webkit2gtk-[\d.]+) was not found']
+    advice: Wails necesita las cabeceras de $1
+    commands: ['sudo apt install lib$1-dev']
+  - id: disk_full
+    disabled: true
+`)
+	writeRules(team, `
+rules:
+  - id: webkit
+    patterns: ['webkit missing']
+  - id: port_in_use
+    title: Puerto ocupado en el equipo
+    patterns: ['port taken']
+`)
+	roots := []ScriptRoot{{Origin: OriginInstall, Path: install}, {Origin: "team:infra", Path: team}}
+
+	rules, errs := LoadDiagnosisRules(roots)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	byID := map[string]DiagnosisRule{}
+	var ids []string
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+		ids = append(ids, rule.ID)
+	}
+	if _, ok := byID["disk_full"]; ok {
+		t.Error("disabled rule still loaded")
+	}
+	if got := byID["webkit"].Patterns; !reflect.DeepEqual(got, []string{"webkit missing"}) {
+		t.Errorf("webkit patterns = %q, want the team rule", got)
+	}
+	if got := byID["webkit"].Title; got != "webkit" {
+		t.Errorf("webkit title = %q, want the id", got)
+	}
+	if got := byID["port_in_use"].Title; got != "Puerto ocupado en el equipo" {
+		t.Errorf("port_in_use title = %q, want the team rule", got)
+	}
+	if ids[0] != "webkit" || ids[1] != "port_in_use" {
+		t.Errorf("rule order = %q, want root rules before the built-ins", ids)
+	}
+
+	found := Diagnose(rules, []string{"listen: port taken"})
+	if len(found) != 1 || found[0].Rule.ID != "port_in_use" {
+		t.Errorf("Diagnose = %+v, want the replaced rule", found)
+	}
+}
+
+func TestLoadDiagnosisRulesInvalidFile(t *testing.T) {
+	tests := map[string]string{
+		"no id":       "rules:\n  - patterns: [x]\n",
+		"repeated id": "rules:\n  - id: a\n    patterns: [x]\n  - id: a\n    patterns: [y]\n",
+		"no patterns": "rules:\n  - id: a\n",
+		"bad regexp":  "rules:\n  - id: a\n    patterns: ['(']\n",
+		"unknown key": "rules:\n  - id: a\n    patterns: [x]\n    severity: high\n",
+		"not a list":  "rules: a\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, diagnosisFileName), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rules, errs := LoadDiagnosisRules([]ScriptRoot{{Origin: OriginInstall, Path: dir}})
+			if len(errs) != 1 {
+				t.Errorf("errs = %v, want one", errs)
+			}
+			if len(rules) != len(builtinDiagnosisRules()) {
+				t.Errorf("%d rules, want only the built-ins", len(rules))
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/ui"
+	"github.com/lucas/launcher/utils"
+)
+
+// envFileName is the per-folder environment file loaded for its scripts
+const envFileName = ".env"
+
+// Variables injected into every script
+const (
+	EnvRoot      = "DEVLAUNCHER_ROOT"
+	EnvRunDir    = "DEVLAUNCHER_RUN_DIR"
+	EnvCategory  = "DEVLAUNCHER_CATEGORY"
+	EnvVersion   = "DEVLAUNCHER_VERSION"
+	EnvScript    = "DEVLAUNCHER_SCRIPT"
+	EnvScriptDir = "DEVLAUNCHER_SCRIPT_DIR"
+)
+
+// Sources of an environment entry, as shown by the env command
+const (
+	envFromLauncher = "launcher"
+	envFromParams   = "parámetros/sesión"
+)
+
+// envEntry is one variable of the environment built for a script
+type envEntry struct {
+	Key    string
+	Value  string
+	Source string // .env path, envFromLauncher or envFromParams
+}
+
+// envFilesFor returns the .env files that apply to the folder rel of a
+// category: from the category folder down to rel, and within each level from
+// the lowest precedence root to the highest, so inner and higher files win
+func envFilesFor(cat Category, rel string) []string {
+	var files []string
+	levels := []string{""}
+	if rel != "" && rel != "." {
+		parts := strings.Split(rel, "/")
+		for i := range parts {
+			levels = append(levels, path.Join(parts[:i+1]...))
+		}
+	}
+	for _, level := range levels {
+		for i := len(cat.Sources) - 1; i >= 0; i-- {
+			file := filepath.Join(cat.Sources[i].Path, filepath.FromSlash(level), envFileName)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// withEnvContext fills the category and .env files of a script that was not
+// listed through its category (favorites, history)
+func withEnvContext(categories []Category, script Script) Script {
+	if script.Category != "" {
+		return script
+	}
+	for _, cat := range categories {
+		if cat.Favorites {
+			continue
+		}
+		if rel, ok := folderRel(cat, filepath.Dir(script.Path)); ok {
+			script.Category = cat.Name
+			script.EnvFiles = envFilesFor(cat, rel)
+			return script
+		}
+	}
+	return script
+}
+
+// scriptEnvEntries builds the variables a script gets on top of the
+// launcher's own environment, in the order they are applied: .env files,
+// DEVLAUNCHER_* variables, then parameters and session overrides. .env files
+// only provide defaults: a variable already in the launcher's environment
+// keeps its value, so FOO=x launcher run ... works.
+func scriptEnvEntries(script Script, runDir string, opts RunOptions) ([]envEntry, []error) {
+	var entries []envEntry
+	var errs []error
+
+	defined := map[string]string{}
+	lookup := func(key string) (string, bool) {
+		if value, ok := defined[key]; ok {
+			return value, true
+		}
+		return os.LookupEnv(key)
+	}
+	add := func(key, value, source string) {
+		defined[key] = value
+		entries = append(entries, envEntry{Key: key, Value: value, Source: source})
+	}
+
+	notInherited := func(key string) bool {
+		_, inherited := os.LookupEnv(key)
+		return !inherited
+	}
+	for _, file := range script.EnvFiles {
+		vars, err := utils.ParseDotEnv(file, lookup, notInherited)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, v := range vars {
+			add(v.Key, v.Value, file)
+		}
+	}
+
+	rootDir := FindRootDir()
+	scriptPath, err := filepath.Abs(script.Path)
+	if err != nil {
+		scriptPath = script.Path
+	}
+	add(EnvRoot, rootDir, envFromLauncher)
+	add(EnvRunDir, runDir, envFromLauncher)
+	add(EnvCategory, script.Category, envFromLauncher)
+	add(EnvVersion, readLauncherVersion(rootDir), envFromLauncher)
+	add(EnvScript, scriptPath, envFromLauncher)
+	add(EnvScriptDir, filepath.Dir(scriptPath), envFromLauncher)
+
+	for _, kv := range opts.Env {
+		key, value, _ := strings.Cut(kv, "=")
+		add(key, value, envFromParams)
+	}
+	return entries, errs
+}
+
+// scriptEnvironment returns the full environment of a script run. A .env
+// file that cannot be read or parsed is an error: the script would otherwise
+// run with part of its variables.
+func scriptEnvironment(script Script, runDir string, opts RunOptions) ([]string, error) {
+	entries, errs := scriptEnvEntries(script, runDir, opts)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	env := os.Environ()
+	for _, entry := range entries {
+		env = append(env, entry.Key+"="+entry.Value)
+	}
+	return env, nil
+}
+
+// effectiveEnv keeps the last value of each variable, sorted by name
+func effectiveEnv(entries []envEntry) []envEntry {
+	last := map[string]envEntry{}
+	for _, entry := range entries {
+		last[entry.Key] = entry
+	}
+	result := make([]envEntry, 0, len(last))
+	for _, entry := range last {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// maskEnvValue hides values of variables that look like secrets
+func maskEnvValue(key, value string) string {
+	upper := strings.ToUpper(key)
+	for _, word := range []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "API_KEY", "PRIVATE"} {
+		if strings.Contains(upper, word) && value != "" {
+			return "••••••"
+		}
+	}
+	return value
+}
+
+// describeEnv renders the environment of a script for the env command
+func describeEnv(script Script, runDir string, opts RunOptions) string {
+	entries, errs := scriptEnvEntries(script, runDir, opts)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Entorno de %s (además del heredado del launcher):\n", script.DisplayName())
+	for _, entry := range effectiveEnv(entries) {
+		source := entry.Source
+		if source != envFromLauncher && source != envFromParams {
+			source = relativeEnvSource(source)
+		}
+		fmt.Fprintf(&b, "  %s=%s  [%s]\n", entry.Key, maskEnvValue(entry.Key, entry.Value), source)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(&b, "  ✗ %v\n", err)
+	}
+	return b.String()
+}
+
+// relativeEnvSource shortens a .env path to its last folders
+func relativeEnvSource(file string) string {
+	dir := filepath.Dir(file)
+	return filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(dir)), filepath.Base(dir), envFileName))
+}
+
+// envCommand implements the env command of CommandMode:
+//
+//	env [N]              environment of the selected script (or item N)
+//	env set KEY=VALUE    override a variable for the next runs of this session
+//	env unset KEY        drop a session override
+//	env edit             open the .env of the current folder in $EDITOR
+func (m *Model) envCommand(args []string) (string, tea.Cmd) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "set":
+			if len(args) < 2 {
+				return ui.ErrorStyle.Render("Uso: env set CLAVE=valor"), nil
+			}
+			kv := strings.Join(args[1:], " ")
+			if !strings.Contains(kv, "=") && len(args) >= 3 {
+				kv = args[1] + "=" + strings.Join(args[2:], " ")
+			}
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok || key == "" || strings.ContainsAny(key, " \t") {
+				return ui.ErrorStyle.Render("Uso: env set CLAVE=valor"), nil
+			}
+			m.unsetEnvOverride(key)
+			m.envOverrides = append(m.envOverrides, key+"="+value)
+			return ui.SuccessStyle.Render("✓ ") + key + "=" + maskEnvValue(key, value) + ui.DimStyle.Render("  (para las próximas ejecuciones)"), nil
+
+		case "unset":
+			if len(args) != 2 {
+				return ui.ErrorStyle.Render("Uso: env unset CLAVE"), nil
+			}
+			if !m.unsetEnvOverride(args[1]) {
+				return ui.ErrorStyle.Render("No hay ninguna variable de sesión " + args[1]), nil
+			}
+			return ui.SuccessStyle.Render("✓ Quitada: ") + args[1], nil
+
+		case "edit":
+			return m.editEnvFile()
+		}
+	}
+
+	script, ok := m.envTarget(args)
+	if !ok {
+		out := "Selecciona un script (o usa env N) para ver su entorno.\n"
+		if m.state == ScriptView {
+			if rel, ok := folderRel(m.currentCategory, m.currentPath); ok {
+				for _, file := range envFilesFor(m.currentCategory, rel) {
+					out += "  .env: " + file + "\n"
+				}
+			}
+		}
+		for _, kv := range m.envOverrides {
+			key, value, _ := strings.Cut(kv, "=")
+			out += fmt.Sprintf("  %s=%s  [%s]\n", key, maskEnvValue(key, value), envFromParams)
+		}
+		return out, nil
+	}
+	script = withEnvContext(m.categories, script)
+	return describeEnv(script, m.runDir, RunOptions{Env: m.envOverrides}), nil
+}
+
+// envTarget returns the script the env command describes
+func (m *Model) envTarget(args []string) (Script, bool) {
+	index := -1
+	if m.state == ScriptView {
+		index = m.scriptList.Index()
+	}
+	if len(args) > 0 {
+		var num int
+		if _, err := fmt.Sscanf(args[0], "%d", &num); err != nil {
+			return Script{}, false
+		}
+		index = num - 1
+	}
+	if m.state != ScriptView || index < 0 || index >= len(m.scripts) {
+		if m.currentScript.Path != "" && len(args) == 0 {
+			return m.currentScript, true
+		}
+		return Script{}, false
+	}
+	if m.scripts[index].Extension == ".dir" {
+		return Script{}, false
+	}
+	return m.scripts[index], true
+}
+
+func (m *Model) unsetEnvOverride(key string) bool {
+	for i, kv := range m.envOverrides {
+		if k, _, _ := strings.Cut(kv, "="); k == key {
+			m.envOverrides = append(m.envOverrides[:i], m.envOverrides[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// editEnvFile opens the .env of the current folder (in its highest-precedence
+// root) in the user's editor, creating it when missing
+func (m *Model) editEnvFile() (string, tea.Cmd) {
+	if m.state != ScriptView || m.currentCategory.Favorites {
+		return ui.ErrorStyle.Render("env edit solo funciona dentro de una carpeta de scripts"), nil
+	}
+	file := filepath.Join(m.currentPath, envFileName)
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "nano"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], file)...)
+	return "Editando " + file, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	})
+}
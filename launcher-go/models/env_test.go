@@ -0,0 +1,123 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, envFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnvFilesFor(t *testing.T) {
+	install, user := t.TempDir(), t.TempDir()
+	cat := Category{Name: "dev", Sources: []ScriptRoot{
+		{Origin: OriginUser, Path: user},
+		{Origin: OriginInstall, Path: install},
+	}}
+	want := []string{
+		writeEnvFile(t, install, "A=1\n"),
+		writeEnvFile(t, user, "A=2\n"),
+		writeEnvFile(t, filepath.Join(install, "go"), "A=3\n"),
+		writeEnvFile(t, filepath.Join(user, "go", "tools"), "A=4\n"),
+	}
+	if err := os.MkdirAll(filepath.Join(install, "go", "tools", envFileName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := envFilesFor(cat, "go/tools"); !reflect.DeepEqual(got, want) {
+		t.Errorf("envFilesFor(go/tools) =\n%q\nwant\n%q", got, want)
+	}
+	if got := envFilesFor(cat, ""); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("envFilesFor(\"\") = %q, want %q", got, want[:2])
+	}
+}
+
+func TestScriptEnvEntries(t *testing.T) {
+	dir := t.TempDir()
+	outer := writeEnvFile(t, dir, "DL_TEST_NAME=outer\nDL_TEST_URL=http://$DL_TEST_NAME\n")
+	inner := writeEnvFile(t, filepath.Join(dir, "sub"), "DL_TEST_NAME=inner\nDL_TEST_GREETING=\"hola ${DL_TEST_NAME}\"\n")
+	script := Script{
+		Name:     "a.sh",
+		Path:     filepath.Join(dir, "sub", "a.sh"),
+		Category: "dev",
+		EnvFiles: []string{outer, inner},
+	}
+
+	entries, errs := scriptEnvEntries(script, "/work", RunOptions{Env: []string{"DL_TEST_NAME=param"}})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	got := map[string]envEntry{}
+	for _, entry := range effectiveEnv(entries) {
+		got[entry.Key] = entry
+	}
+	for key, want := range map[string]envEntry{
+		"DL_TEST_NAME":     {"DL_TEST_NAME", "param", envFromParams},
+		"DL_TEST_URL":      {"DL_TEST_URL", "http://outer", outer},
+		"DL_TEST_GREETING": {"DL_TEST_GREETING", "hola inner", inner},
+		EnvRunDir:          {EnvRunDir, "/work", envFromLauncher},
+		EnvCategory:        {EnvCategory, "dev", envFromLauncher},
+		EnvScriptDir:       {EnvScriptDir, filepath.Join(dir, "sub"), envFromLauncher},
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %+v, want %+v", key, got[key], want)
+		}
+	}
+}
+
+func TestScriptEnvironmentInheritedWins(t *testing.T) {
+	t.Setenv("DL_TEST_NAME", "shell")
+	dir := t.TempDir()
+	script := Script{Name: "a.sh", Path: filepath.Join(dir, "a.sh"), EnvFiles: []string{
+		writeEnvFile(t, dir, "DL_TEST_NAME=dotenv\nDL_TEST_OTHER=$DL_TEST_NAME\n"),
+	}}
+
+	env, err := scriptEnvironment(script, dir, RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{}
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		values[key] = value
+	}
+	if values["DL_TEST_NAME"] != "shell" || values["DL_TEST_OTHER"] != "shell" {
+		t.Errorf("DL_TEST_NAME = %q, DL_TEST_OTHER = %q; want the inherited value", values["DL_TEST_NAME"], values["DL_TEST_OTHER"])
+	}
+}
+
+func TestScriptEnvironmentBrokenEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	script := Script{Name: "a.sh", Path: filepath.Join(dir, "a.sh"), EnvFiles: []string{
+		writeEnvFile(t, dir, "DL_TEST_NAME=ok\nnot a variable\n"),
+	}}
+	if _, err := scriptEnvironment(script, dir, RunOptions{}); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("scriptEnvironment error = %v, want the broken line", err)
+	}
+}
+
+func TestMaskEnvValue(t *testing.T) {
+	tests := []struct{ key, value, want string }{
+		{"GITHUB_TOKEN", "abc", "••••••"},
+		{"db_password", "abc", "••••••"},
+		{"API_KEY", "", ""},
+		{"PATH", "/bin", "/bin"},
+	}
+	for _, tt := range tests {
+		if got := maskEnvValue(tt.key, tt.value); got != tt.want {
+			t.Errorf("maskEnvValue(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
@@ -2,81 +2,385 @@ package models
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
-	"runtime"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucas/launcher/config"
 )
 
-// getScriptCommand returns the command to execute a script
-func getScriptCommand(script Script, workingDir string) *exec.Cmd {
-	var cmd *exec.Cmd
+// maxCapturedOutput caps how many bytes of script output are kept for the result view
+const maxCapturedOutput = 256 * 1024
 
-	switch script.Extension {
-	case ".sh":
-		cmd = exec.Command("bash", script.Path)
-	case ".ps1":
-		// Try pwsh first, fallback to powershell
-		if _, err := exec.LookPath("pwsh"); err == nil {
-			cmd = exec.Command("pwsh", "-ExecutionPolicy", "Bypass", "-File", script.Path)
-		} else {
-			cmd = exec.Command("powershell", "-ExecutionPolicy", "Bypass", "-File", script.Path)
+// getScriptCommand returns the command to execute a script: the file itself
+// when it runs directly (its #! line picks the interpreter), otherwise
+// through its interpreter
+func getScriptCommand(script Script, workingDir string, opts RunOptions) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if script.Direct {
+		path, err := filepath.Abs(script.Path)
+		if err != nil {
+			return nil, err
 		}
-	case ".bat":
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("cmd.exe", "/c", script.Path)
-		} else {
-			cmd = exec.Command("cmd.exe", "/c", script.Path) // WSL scenario
+		cmd = exec.Command(path)
+	} else {
+		in, err := scriptInterpreter(script)
+		if err != nil {
+			return nil, err
 		}
-	default:
-		// Return a command that will fail with a clear error
-		cmd = exec.Command("echo", fmt.Sprintf("unsupported script extension: %s", script.Extension))
+		argv, err := in.Argv(script.Path, nil)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.Command(argv[0], argv[1:]...)
 	}
-
-	if workingDir != "" {
-		cmd.Dir = workingDir
+	cmd.Args = append(cmd.Args, script.Args...)
+	cmd.Args = append(cmd.Args, opts.Args...)
+	env, err := scriptEnvironment(script, workingDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = env
+	if dir := resolveWorkingDir(script, workingDir); dir != "" {
+		cmd.Dir = dir
 	}
 
-	return cmd
+	return cmd, nil
 }
 
-// ExecuteScript executes a script and returns the exit code and combined output (stdout+stderr)
-func ExecuteScript(script Script, workingDir string) (int, string) {
-	var cmd *exec.Cmd
+// resolveWorkingDir applies the script's @cwd header to the launcher run dir.
+// "script" runs next to the script file, any other value is a path relative to
+// the script folder; empty, "run" or "." keep the launcher run dir.
+func resolveWorkingDir(script Script, runDir string) string {
+	cwd := strings.TrimSpace(script.Cwd)
+	switch strings.ToLower(cwd) {
+	case "", ".", "run", "launch":
+		return runDir
+	case "script":
+		return filepath.Dir(script.Path)
+	}
 
-	switch script.Extension {
-	case ".sh":
-		cmd = exec.Command("bash", script.Path)
-	case ".ps1":
-		// Try pwsh first, fallback to powershell
-		if _, err := exec.LookPath("pwsh"); err == nil {
-			cmd = exec.Command("pwsh", "-ExecutionPolicy", "Bypass", "-File", script.Path)
-		} else {
-			cmd = exec.Command("powershell", "-ExecutionPolicy", "Bypass", "-File", script.Path)
+	if strings.HasPrefix(cwd, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			cwd = filepath.Join(home, strings.TrimPrefix(cwd, "~"))
 		}
-	case ".bat":
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("cmd.exe", "/c", script.Path)
-		} else {
-			cmd = exec.Command("cmd.exe", "/c", script.Path) // WSL scenario
+	}
+	if !filepath.IsAbs(cwd) {
+		cwd = filepath.Join(filepath.Dir(script.Path), cwd)
+	}
+	return cwd
+}
+
+// ExecuteScript executes a script attached to the current terminal and returns
+// the exit code and the captured output (stdout+stderr)
+func ExecuteScript(script Script, workingDir string, opts RunOptions) (int, string) {
+	run, err := newScriptRun(script, workingDir, opts)
+	if err != nil {
+		return 1, err.Error()
+	}
+
+	run.SetStdin(os.Stdin)
+	run.SetStdout(os.Stdout)
+	run.SetStderr(os.Stderr)
+
+	return run.Result(run.Run())
+}
+
+// Run statuses recorded in ResultView and the history
+const (
+	RunOK        = "ok"
+	RunFailed    = "failed"
+	RunCancelled = "cancelled"
+	RunTimedOut  = "timeout"
+)
+
+// runMode returns how a script is run: its @mode header, else run_mode from the config
+func runMode(script Script) string {
+	switch script.Mode {
+	case config.RunModeTerminal, config.RunModeCapture, config.RunModeBackground:
+		return script.Mode
+	}
+	return config.Current().RunMode
+}
+
+// runTimeout returns the script's @timeout, else the default timeout from the config
+func runTimeout(script Script) time.Duration {
+	if script.Timeout > 0 {
+		return script.Timeout
+	}
+	return time.Duration(config.Current().Timeout)
+}
+
+// scriptRun executes a script once while teeing its output to the terminal
+// and into a bounded capture buffer. It satisfies tea.ExecCommand so the TUI
+// can hand the terminal over to the script via tea.Exec.
+//
+// The script runs in its own process group. Cancel (or the timeout) sends
+// SIGINT to the whole group and SIGKILL once kill_grace has passed.
+type scriptRun struct {
+	cmd      *exec.Cmd
+	output   *outputCapture
+	script   Script
+	runDir   string
+	opts     RunOptions
+	timeout  time.Duration
+	grace    time.Duration
+	started  time.Time
+	finished time.Time
+
+	mu         sync.Mutex
+	pid        int
+	stopReason string // RunCancelled or RunTimedOut once a stop was requested
+	killed     bool
+	done       chan struct{}
+}
+
+func newScriptRun(script Script, workingDir string, opts RunOptions) (*scriptRun, error) {
+	cmd, err := getScriptCommand(script, workingDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &scriptRun{
+		cmd:     cmd,
+		output:  newOutputCapture(maxCapturedOutput),
+		script:  script,
+		runDir:  workingDir,
+		opts:    opts,
+		timeout: runTimeout(script),
+		grace:   time.Duration(config.Current().KillGrace),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+func (r *scriptRun) Run() error {
+	r.mu.Lock()
+	r.started = time.Now()
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.finished = time.Now()
+		r.mu.Unlock()
+	}()
+	defer close(r.done)
+
+	foreground := configureProcessGroup(r.cmd)
+	if err := r.cmd.Start(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.pid = r.cmd.Process.Pid
+	r.mu.Unlock()
+	if r.timeout > 0 {
+		timer := time.AfterFunc(r.timeout, func() { r.stop(RunTimedOut) })
+		defer timer.Stop()
+	}
+
+	err := r.cmd.Wait()
+	if foreground {
+		reclaimTerminal(r.cmd)
+	}
+	if r.StopReason() != "" {
+		// Do not leave children of a cancelled script behind
+		killProcessGroup(r.cmd)
+	}
+	return err
+}
+
+// Cancel interrupts the script. A second call kills it without waiting.
+func (r *scriptRun) Cancel() {
+	r.mu.Lock()
+	again := r.stopReason != ""
+	r.mu.Unlock()
+	if again {
+		r.kill()
+		return
+	}
+	r.stop(RunCancelled)
+}
+
+// stop sends SIGINT to the process group and SIGKILL after the grace period
+func (r *scriptRun) stop(reason string) {
+	r.mu.Lock()
+	if r.stopReason != "" {
+		r.mu.Unlock()
+		return
+	}
+	r.stopReason = reason
+	r.mu.Unlock()
+
+	interruptProcessGroup(r.cmd)
+	go func() {
+		select {
+		case <-r.done:
+		case <-time.After(r.grace):
+			r.kill()
 		}
-	default:
-		return 1, fmt.Sprintf("unsupported script extension: %s", script.Extension)
+	}()
+}
+
+func (r *scriptRun) kill() {
+	r.mu.Lock()
+	r.killed = true
+	r.mu.Unlock()
+	killProcessGroup(r.cmd)
+}
+
+// StopReason returns RunCancelled or RunTimedOut when a stop was requested
+func (r *scriptRun) StopReason() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopReason
+}
+
+// Elapsed returns how long the script has been running, or ran once finished
+func (r *scriptRun) Elapsed() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started.IsZero() {
+		return 0
+	}
+	if !r.finished.IsZero() {
+		return r.finished.Sub(r.started)
 	}
+	return time.Since(r.started)
+}
 
-	if workingDir != "" {
-		cmd.Dir = workingDir
+// PID returns the process id of the script, 0 until it started
+func (r *scriptRun) PID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pid
+}
+
+func (r *scriptRun) SetStdin(reader io.Reader) {
+	if r.cmd.Stdin == nil {
+		r.cmd.Stdin = reader
 	}
+}
 
-	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+func (r *scriptRun) SetStdout(writer io.Writer) {
+	r.cmd.Stdout = io.MultiWriter(writer, r.output)
+}
+
+func (r *scriptRun) SetStderr(writer io.Writer) {
+	r.cmd.Stderr = io.MultiWriter(writer, r.output)
+}
 
+// Result returns the exit code of the finished process and its captured output.
+// runErr is the error returned by Run (or by the terminal handover around it).
+func (r *scriptRun) Result(runErr error) (int, string) {
 	exitCode := 0
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
-		} else {
+	if r.cmd.ProcessState != nil {
+		exitCode, _ = exitCodeOf(r.cmd.ProcessState)
+		if exitCode < 0 {
 			exitCode = 1
 		}
+	} else if runErr != nil {
+		// The process never started (missing interpreter, bad working dir...)
+		exitCode = 1
+		r.output.Write([]byte(runErr.Error() + "\n"))
+	}
+
+	switch r.Status() {
+	case RunTimedOut:
+		r.output.Write([]byte(fmt.Sprintf("\n... tiempo límite de %s agotado, script detenido\n", r.timeout)))
+	case RunCancelled:
+		if r.StopReason() != "" {
+			r.output.Write([]byte("\n... ejecución cancelada\n"))
+		}
+	}
+
+	return exitCode, r.output.String()
+}
+
+// Status returns RunOK, RunFailed, RunCancelled or RunTimedOut for the finished run.
+// A script stopped with Ctrl+C from the terminal counts as cancelled.
+func (r *scriptRun) Status() string {
+	if reason := r.StopReason(); reason != "" {
+		return reason
 	}
+	if r.cmd.ProcessState == nil {
+		return RunFailed
+	}
+	code, interrupted := exitCodeOf(r.cmd.ProcessState)
+	switch {
+	case interrupted:
+		return RunCancelled
+	case code != 0:
+		return RunFailed
+	}
+	return RunOK
+}
+
+// HistoryEntry returns the history record of the finished run
+func (r *scriptRun) HistoryEntry(exitCode int, output, source string) HistoryEntry {
+	started, finished := r.started, r.finished
+	if started.IsZero() {
+		started = time.Now()
+		finished = started
+	}
+	entry := newHistoryEntry(r.script, r.runDir, r.opts, started, finished, exitCode, output, source)
+	entry.Status = r.Status()
+	return entry
+}
 
-	return exitCode, string(output)
+// outputCapture records written bytes, keeping only the most recent limit bytes
+type outputCapture struct {
+	mu        sync.Mutex
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func newOutputCapture(limit int) *outputCapture {
+	return &outputCapture{limit: limit}
+}
+
+func (c *outputCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf = append(c.buf, p...)
+	if c.limit > 0 && len(c.buf) > c.limit {
+		c.buf = append([]byte(nil), c.buf[len(c.buf)-c.limit:]...)
+		c.truncated = true
+	}
+	return len(p), nil
+}
+
+func (c *outputCapture) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.truncated {
+		return fmt.Sprintf("... (salida truncada, se muestran los últimos %d KB) ...\n", c.limit/1024) + string(c.buf)
+	}
+	return string(c.buf)
+}
+
+// Tail returns the last lines of the captured output, keeping only what
+// follows the last carriage return of each line (progress bars)
+func (c *outputCapture) Tail(lines int) []string {
+	c.mu.Lock()
+	text := string(c.buf)
+	c.mu.Unlock()
+
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	for i, line := range all {
+		if idx := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+		all[i] = strings.TrimRight(line, "\r")
+	}
+	if len(all) == 1 && all[0] == "" {
+		return nil
+	}
+	return all
 }
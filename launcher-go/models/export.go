@@ -0,0 +1,233 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lucas/launcher/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats accepted by "launcher --list --format"
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTSV  = "tsv"
+)
+
+// ListDocument is the machine-readable listing of the whole scripts tree
+type ListDocument struct {
+	Root     string       `json:"root" yaml:"root"`
+	Roots    []ScriptRoot `json:"roots" yaml:"roots"`
+	Platform string       `json:"platform" yaml:"platform"`
+	Entries  []ListEntry  `json:"entries" yaml:"entries"`
+}
+
+// ListEntry describes one category, folder or script of the tree. Path is
+// relative to the platform scripts folder and slash-separated; Origins lists
+// the roots the item comes from.
+type ListEntry struct {
+	Type        string       `json:"type" yaml:"type"`
+	Category    string       `json:"category" yaml:"category"`
+	Path        string       `json:"path" yaml:"path"`
+	Name        string       `json:"name" yaml:"name"`
+	Title       string       `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string       `json:"description" yaml:"description"`
+	Icon        string       `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Extension   string       `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Interpreter string       `json:"interpreter,omitempty" yaml:"interpreter,omitempty"`
+	DirCount    int          `json:"dirCount" yaml:"dirCount"`
+	ScriptCount int          `json:"scriptCount" yaml:"scriptCount"`
+	Tags        []string     `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Requires    []string     `json:"requires,omitempty" yaml:"requires,omitempty"`
+	RequiresEnv []string     `json:"requiresEnv,omitempty" yaml:"requiresEnv,omitempty"`
+	RequiresOS  []string     `json:"requiresOS,omitempty" yaml:"requiresOS,omitempty"`
+	MinLauncher string       `json:"requiresLauncher,omitempty" yaml:"requiresLauncher,omitempty"`
+	Root        bool         `json:"root,omitempty" yaml:"root,omitempty"`
+	Sudo        bool         `json:"sudo,omitempty" yaml:"sudo,omitempty"`
+	Unmet       []string     `json:"unmet,omitempty" yaml:"unmet,omitempty"`
+	Confirm     bool         `json:"confirm,omitempty" yaml:"confirm,omitempty"`
+	Timeout     string       `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Cwd         string       `json:"cwd,omitempty" yaml:"cwd,omitempty"`
+	Args        []string     `json:"args,omitempty" yaml:"args,omitempty"`
+	Params      []ParamEntry `json:"params,omitempty" yaml:"params,omitempty"`
+	Origins     []string     `json:"origins" yaml:"origins"`
+	Overrides   []string     `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// ParamEntry is the exported form of a ScriptParam
+type ParamEntry struct {
+	Name     string   `json:"name" yaml:"name"`
+	Label    string   `json:"label" yaml:"label"`
+	Type     string   `json:"type" yaml:"type"`
+	Default  string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Choices  []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	Required bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Env      string   `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Entry types of ListEntry
+const (
+	EntryCategory = "category"
+	EntryDir      = "dir"
+	EntryScript   = "script"
+	EntryWorkflow = "workflow"
+)
+
+// BuildListDocument scans every category of every root recursively
+func BuildListDocument(roots []ScriptRoot) (ListDocument, error) {
+	doc := ListDocument{
+		Roots:    roots,
+		Platform: string(utils.DetectPlatform()),
+		Entries:  []ListEntry{},
+	}
+	if len(roots) > 0 {
+		doc.Root = roots[0].Path
+	}
+
+	categories, err := ScanCategories(roots)
+	if err != nil {
+		return doc, err
+	}
+
+	for _, cat := range categories {
+		doc.Entries = append(doc.Entries, ListEntry{
+			Type:        EntryCategory,
+			Category:    cat.Name,
+			Path:        cat.Name,
+			Name:        cat.Name,
+			Description: cat.Description,
+			Icon:        cat.Icon,
+			DirCount:    cat.DirCount,
+			ScriptCount: cat.ScriptCount,
+			Origins:     sourceOrigins(cat.Sources),
+		})
+		WalkTree([]Category{cat}, func(cat Category, rel string, item Script) {
+			path := cat.Name + "/" + rel
+			if item.Extension == ".dir" {
+				doc.Entries = append(doc.Entries, ListEntry{
+					Type:        EntryDir,
+					Category:    cat.Name,
+					Path:        path,
+					Name:        item.Name,
+					Description: item.Description,
+					Icon:        item.Icon,
+					DirCount:    item.DirCount,
+					ScriptCount: item.ScriptCount,
+					Origins:     sourceOrigins(item.Sources),
+				})
+				return
+			}
+			doc.Entries = append(doc.Entries, scriptListEntry(path, cat.Name, item))
+		})
+	}
+
+	return doc, nil
+}
+
+func scriptListEntry(path, category string, script Script) ListEntry {
+	entry := ListEntry{
+		Type:        EntryScript,
+		Category:    category,
+		Path:        path,
+		Name:        script.Name,
+		Title:       script.Title,
+		Description: script.Description,
+		Icon:        script.Icon,
+		Extension:   script.Extension,
+		Interpreter: script.Interpreter,
+		Tags:        script.Tags,
+		Requires:    script.Requires,
+		RequiresEnv: script.RequiresEnv,
+		RequiresOS:  script.RequiresOS,
+		MinLauncher: script.MinLauncher,
+		Root:        script.Root,
+		Sudo:        script.Sudo,
+		Unmet:       script.Unmet,
+		Confirm:     script.Confirm,
+		Cwd:         script.Cwd,
+		Args:        script.Args,
+		Origins:     []string{script.Origin},
+		Overrides:   script.Overrides,
+	}
+	if script.Timeout > 0 {
+		entry.Timeout = script.Timeout.String()
+	}
+	if script.IsWorkflow() {
+		entry.Type = EntryWorkflow
+		entry.ScriptCount = script.ScriptCount
+	}
+	for _, param := range script.Params {
+		entry.Params = append(entry.Params, ParamEntry{
+			Name:     param.Name,
+			Label:    param.Label,
+			Type:     param.Type,
+			Default:  param.Default,
+			Choices:  param.Choices,
+			Required: param.Required,
+			Env:      param.Env,
+		})
+	}
+	return entry
+}
+
+// WriteListDocument writes the listing in the requested format, without styling
+func WriteListDocument(w io.Writer, doc ListDocument, format string) error {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatYAML, "yml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatTSV:
+		return writeListTSV(w, doc)
+	}
+	return fmt.Errorf("formato no soportado: %s (usa json, yaml o tsv)", format)
+}
+
+var tsvColumns = []string{"type", "category", "path", "name", "title", "description", "icon", "extension", "dirs", "scripts", "tags", "requires", "confirm", "timeout", "origins", "interpreter"}
+
+func writeListTSV(w io.Writer, doc ListDocument) error {
+	if _, err := fmt.Fprintln(w, strings.Join(tsvColumns, "\t")); err != nil {
+		return err
+	}
+	for _, e := range doc.Entries {
+		fields := []string{
+			e.Type, e.Category, e.Path, e.Name, e.Title, e.Description, e.Icon, e.Extension,
+			strconv.Itoa(e.DirCount), strconv.Itoa(e.ScriptCount),
+			strings.Join(e.Tags, ","), strings.Join(e.Requires, ","),
+			strconv.FormatBool(e.Confirm), e.Timeout, strings.Join(e.Origins, ","), e.Interpreter,
+		}
+		for i, field := range fields {
+			fields[i] = tsvEscape(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tsvEscape(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r").Replace(value)
+}
+
+// ExportScripts writes the merged scripts tree of every root in the given format
+func ExportScripts(w io.Writer, format string) error {
+	cwd, _ := os.Getwd()
+	doc, err := BuildListDocument(ResolveScriptRoots(FindRootDir(), cwd))
+	if err != nil {
+		return err
+	}
+	return WriteListDocument(w, doc, format)
+}
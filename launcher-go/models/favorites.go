@@ -0,0 +1,184 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FavoritesCategoryName is the pseudo-category listing starred scripts and folders
+const FavoritesCategoryName = "Favoritos"
+
+// favoritesFile is the on-disk format of the favorites file
+type favoritesFile struct {
+	Favorites []string `json:"favorites"`
+}
+
+// LoadFavorites reads the starred paths. A missing file means no favorites.
+func LoadFavorites(favoritesPath string) ([]string, error) {
+	data, err := os.ReadFile(favoritesPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file favoritesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return file.Favorites, nil
+}
+
+// SaveFavorites writes the starred paths
+func SaveFavorites(favoritesPath string, favorites []string) error {
+	if err := os.MkdirAll(filepath.Dir(favoritesPath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(favoritesFile{Favorites: favorites}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(favoritesPath, append(data, '\n'), 0644)
+}
+
+// ToggleFavorite adds path to favorites, or removes it when already starred.
+// It returns the new list and whether path is now a favorite.
+func ToggleFavorite(favorites []string, path string) ([]string, bool) {
+	for i, fav := range favorites {
+		if fav == path {
+			return append(favorites[:i:i], favorites[i+1:]...), false
+		}
+	}
+	return append(favorites, path), true
+}
+
+// ScanFavorites builds the items of the Favoritos pseudo-category. Entries
+// whose file no longer exists are skipped.
+func ScanFavorites(favorites []string) []Script {
+	var items []Script
+	for _, path := range favorites {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			dirCount, scriptCount := countImmediateItems(path)
+			items = append(items, Script{
+				Name:        filepath.Base(path),
+				Path:        path,
+				Description: folderDescriptionFromREADME(path, filepath.Base(path)),
+				Extension:   ".dir",
+				Icon:        folderIconFromREADME(path, filepath.Base(path)),
+				DirCount:    dirCount,
+				ScriptCount: scriptCount,
+			})
+			continue
+		}
+		items = append(items, newScript(path, filepath.Ext(path)))
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		iDir := items[i].Extension == ".dir"
+		jDir := items[j].Extension == ".dir"
+		if iDir != jDir {
+			return iDir
+		}
+		return strings.ToLower(items[i].DisplayName()) < strings.ToLower(items[j].DisplayName())
+	})
+	return items
+}
+
+// favoritesCategory returns the pseudo-category shown first in CategoryView
+func favoritesCategory(favorites []string) (Category, bool) {
+	items := ScanFavorites(favorites)
+	if len(items) == 0 {
+		return Category{}, false
+	}
+
+	cat := Category{
+		Name:        FavoritesCategoryName,
+		Icon:        "⭐",
+		Description: "Scripts y carpetas marcados con f",
+		Favorites:   true,
+	}
+	for _, item := range items {
+		if item.Extension == ".dir" {
+			cat.DirCount++
+		} else {
+			cat.ScriptCount++
+		}
+	}
+	return cat, true
+}
+
+// isFavorite reports whether path is starred
+func (m Model) isFavorite(path string) bool {
+	for _, fav := range m.favorites {
+		if fav == path {
+			return true
+		}
+	}
+	return false
+}
+
+// insideFavorite reports whether path is a starred folder or lies below one
+func (m Model) insideFavorite(path string) bool {
+	for _, fav := range m.favorites {
+		if path == fav || strings.HasPrefix(path, fav+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// withFavorites returns the categories with the Favoritos pseudo-category first
+func (m Model) withFavorites(categories []Category) []Category {
+	scanned := make([]Category, 0, len(categories)+1)
+	for _, cat := range categories {
+		if !cat.Favorites {
+			scanned = append(scanned, cat)
+		}
+	}
+	if fav, ok := favoritesCategory(m.favorites); ok {
+		return append([]Category{fav}, scanned...)
+	}
+	return scanned
+}
+
+// toggleFavorite stars or unstars a script or folder and persists the change
+func (m *Model) toggleFavorite(item Script) (tea.Cmd, bool) {
+	var starred bool
+	m.favorites, starred = ToggleFavorite(m.favorites, item.Path)
+
+	m.categories = m.withFavorites(m.categories)
+	m.categoryList = m.createCategoryList()
+
+	cmds := []tea.Cmd{saveFavorites(m.favoritesPath, m.favorites)}
+	if m.currentCategory.Favorites && m.currentPath == "" {
+		cmds = append(cmds, loadFavoriteScripts(m.favorites))
+	}
+	return tea.Batch(cmds...), starred
+}
+
+func saveFavorites(favoritesPath string, favorites []string) tea.Cmd {
+	saved := append([]string(nil), favorites...)
+	return func() tea.Msg {
+		if err := SaveFavorites(favoritesPath, saved); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
+}
+
+func loadFavoriteScripts(favorites []string) tea.Cmd {
+	paths := append([]string(nil), favorites...)
+	return func() tea.Msg {
+		return scriptsLoadedMsg{scripts: ScanFavorites(paths)}
+	}
+}
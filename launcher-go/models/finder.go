@@ -0,0 +1,363 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucas/launcher/ui"
+	"github.com/sahilm/fuzzy"
+)
+
+// Weights added to the fuzzy score depending on the field that matched
+const (
+	finderWeightName        = 40
+	finderWeightTags        = 25
+	finderWeightDescription = 10
+	finderWeightPath        = 0
+	finderSubstringBonus    = 50
+	finderMaxResults        = 50
+)
+
+// searchItem is a script or folder of the whole tree, as indexed by the finder
+type searchItem struct {
+	script   Script
+	category string
+	path     string // slash-separated logical path, category first
+}
+
+// searchResult is a ranked match with the byte offsets to highlight
+type searchResult struct {
+	item      searchItem
+	score     int
+	nameMatch []int
+	pathMatch []int
+}
+
+// Finder is the global fuzzy search over every script under the scripts root
+type Finder struct {
+	input    textinput.Model
+	items    []searchItem
+	results  []searchResult
+	selected int
+	loading  bool
+}
+
+// NewFinder creates an empty finder with the query prefilled
+func NewFinder(query string) Finder {
+	ti := textinput.New()
+	ti.Placeholder = "buscar scripts y carpetas..."
+	ti.Prompt = "/ "
+	ti.CharLimit = 100
+	ti.Width = 50
+	ti.SetValue(query)
+	ti.CursorEnd()
+	ti.Focus()
+	return Finder{input: ti, loading: true}
+}
+
+// SetItems loads the index and refreshes the results
+func (f *Finder) SetItems(items []searchItem) {
+	f.items = items
+	f.loading = false
+	f.refresh()
+}
+
+// BuildSearchIndex walks every category of every root, folders included
+func BuildSearchIndex(categories []Category) []searchItem {
+	var items []searchItem
+	WalkTree(categories, func(cat Category, rel string, item Script) {
+		items = append(items, searchItem{
+			script:   item,
+			category: cat.Name,
+			path:     cat.Name + "/" + rel,
+		})
+	})
+	return items
+}
+
+// rankSearchItems scores every item against the query. Each space-separated
+// term must match the name, tags, description or path of the item.
+func rankSearchItems(items []searchItem, query string) []searchResult {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []searchResult
+	for _, item := range items {
+		result := searchResult{item: item}
+		matchedAll := true
+		for _, term := range terms {
+			score, nameMatch, pathMatch, ok := scoreSearchTerm(item, term)
+			if !ok {
+				matchedAll = false
+				break
+			}
+			result.score += score
+			result.nameMatch = append(result.nameMatch, nameMatch...)
+			result.pathMatch = append(result.pathMatch, pathMatch...)
+		}
+		if matchedAll {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].item.path < results[j].item.path
+	})
+	if len(results) > finderMaxResults {
+		results = results[:finderMaxResults]
+	}
+	return results
+}
+
+// scoreSearchTerm returns the best weighted score of term across the item fields
+func scoreSearchTerm(item searchItem, term string) (int, []int, []int, bool) {
+	best := 0
+	found := false
+	var nameMatch, pathMatch []int
+
+	try := func(text string, weight int) (fuzzy.Match, bool) {
+		if text == "" {
+			return fuzzy.Match{}, false
+		}
+		matches := fuzzy.Find(term, []string{text})
+		if len(matches) == 0 {
+			return fuzzy.Match{}, false
+		}
+		score := matches[0].Score + weight
+		if strings.Contains(strings.ToLower(text), strings.ToLower(term)) {
+			score += finderSubstringBonus
+		}
+		if !found || score > best {
+			best = score
+			found = true
+		}
+		return matches[0], true
+	}
+
+	if match, ok := try(item.script.DisplayName(), finderWeightName); ok {
+		nameMatch = match.MatchedIndexes
+	}
+	try(strings.Join(item.script.Tags, " "), finderWeightTags)
+	try(item.script.Description, finderWeightDescription)
+	if match, ok := try(item.path, finderWeightPath); ok {
+		pathMatch = match.MatchedIndexes
+	}
+
+	return best, nameMatch, pathMatch, found
+}
+
+func (f *Finder) refresh() {
+	f.results = rankSearchItems(f.items, f.input.Value())
+	if f.selected >= len(f.results) {
+		f.selected = len(f.results) - 1
+	}
+	if f.selected < 0 {
+		f.selected = 0
+	}
+}
+
+// Selected returns the highlighted result
+func (f Finder) Selected() (searchItem, bool) {
+	if f.selected < 0 || f.selected >= len(f.results) {
+		return searchItem{}, false
+	}
+	return f.results[f.selected].item, true
+}
+
+// Update handles a key press while the finder is open. It reports whether the
+// user picked a result (run/open it, or only open its folder) or closed the finder.
+func (f *Finder) Update(msg tea.KeyMsg) (picked bool, folderOnly bool, closed bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return false, false, true, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if f.selected > 0 {
+			f.selected--
+		}
+		return false, false, false, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if f.selected < len(f.results)-1 {
+			f.selected++
+		}
+		return false, false, false, nil
+	case "enter":
+		_, ok := f.Selected()
+		return ok, false, false, nil
+	case "tab", "ctrl+o":
+		_, ok := f.Selected()
+		return ok, true, false, nil
+	}
+
+	before := f.input.Value()
+	f.input, cmd = f.input.Update(msg)
+	if f.input.Value() != before {
+		f.selected = 0
+		f.refresh()
+	}
+	return false, false, false, cmd
+}
+
+// View renders the finder
+func (f Finder) View(height int) string {
+	content := ui.TitleStyle.Render("🔎 Buscar en todos los scripts") + "\n"
+	content += f.input.View() + "\n\n"
+
+	switch {
+	case f.loading:
+		content += ui.DimStyle.Render("Indexando scripts...") + "\n"
+	case strings.TrimSpace(f.input.Value()) == "":
+		content += ui.DimStyle.Render(fmt.Sprintf("%d elementos indexados. Escribe para buscar por nombre, descripción, tags o ruta.", len(f.items))) + "\n"
+	case len(f.results) == 0:
+		content += ui.ErrorStyle.Render("✗ Sin resultados") + "\n"
+	default:
+		visible := (height - 12) / 2
+		if visible < 3 {
+			visible = 3
+		}
+		start := 0
+		if f.selected >= visible {
+			start = f.selected - visible + 1
+		}
+		end := start + visible
+		if end > len(f.results) {
+			end = len(f.results)
+		}
+
+		for i := start; i < end; i++ {
+			result := f.results[i]
+			script := result.item.script
+			selected := i == f.selected
+
+			icon := "📄"
+			if script.Extension == ".dir" {
+				icon = script.Icon
+				if icon == "" {
+					icon = "📂"
+				}
+			}
+
+			baseStyle := ui.ExecutableStyle
+			if script.Extension == ".dir" {
+				baseStyle = ui.DirectoryStyle
+			}
+			prefix := ui.NormalStyle.Render("  ")
+			if selected {
+				prefix = ui.SelectedStyle.Render("› ")
+				baseStyle = ui.SelectedExecutableStyle
+				if script.Extension == ".dir" {
+					baseStyle = ui.SelectedDirectoryStyle
+				}
+			}
+
+			line := prefix + icon + " " + highlightMatches(script.DisplayName(), result.nameMatch, baseStyle)
+			line += "  " + highlightMatches(result.item.path, result.pathMatch, ui.DimStyle)
+			badge := originBadge(script.Origin)
+			if script.Extension == ".dir" {
+				badge = originBadge(sourceOrigins(script.Sources)...)
+			}
+			if badge != "" {
+				line += "  " + ui.CountStyle.Render(badge)
+			}
+			content += line + "\n"
+			if details := scriptDetails(script); details != "" {
+				content += ui.DimStyle.Render("     "+details) + "\n"
+			}
+		}
+		if len(f.results) > visible {
+			content += ui.DimStyle.Render(fmt.Sprintf("  [%d-%d de %d]", start+1, end, len(f.results))) + "\n"
+		}
+	}
+
+	content += "\n" + ui.DimStyle.Render("↑↓: navegar  enter: ejecutar/abrir  tab: ir a la carpeta  esc: cerrar")
+	return content
+}
+
+// highlightMatches renders text with the matched byte offsets emphasised
+func highlightMatches(text string, matched []int, base lipgloss.Style) string {
+	if len(matched) == 0 {
+		return base.Render(text)
+	}
+	marks := make(map[int]bool, len(matched))
+	for _, idx := range matched {
+		marks[idx] = true
+	}
+
+	var sb strings.Builder
+	var run strings.Builder
+	runMatched := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runMatched {
+			sb.WriteString(ui.MatchStyle.Render(run.String()))
+		} else {
+			sb.WriteString(base.Render(run.String()))
+		}
+		run.Reset()
+	}
+	for i, r := range text {
+		if marks[i] != runMatched {
+			flush()
+			runMatched = marks[i]
+		}
+		run.WriteRune(r)
+	}
+	flush()
+	return sb.String()
+}
+
+// openFinder shows the global search, indexing the tree in the background
+func (m *Model) openFinder(query string) tea.Cmd {
+	if m.state != FinderView {
+		m.finderReturn = m.state
+	}
+	m.finder = NewFinder(query)
+	m.state = FinderView
+	return tea.Batch(textinput.Blink, buildSearchIndexCmd(m.categories))
+}
+
+// pickSearchResult jumps to the folder of the picked item and runs it when it is a script
+func (m *Model) pickSearchResult(item searchItem, folderOnly bool) tea.Cmd {
+	for _, cat := range m.categories {
+		if !cat.Favorites && cat.Name == item.category {
+			m.currentCategory = cat
+			break
+		}
+	}
+	m.headerShown = true
+	m.state = ScriptView
+
+	if item.script.Extension == ".dir" {
+		m.currentPath = item.script.Path
+		return m.loadCurrentFolder()
+	}
+
+	m.currentPath = filepath.Dir(item.script.Path)
+	load := m.loadCurrentFolder()
+	if folderOnly {
+		return load
+	}
+	return tea.Batch(load, m.startScript(item.script))
+}
+
+type searchIndexMsg struct {
+	items []searchItem
+}
+
+func buildSearchIndexCmd(categories []Category) tea.Cmd {
+	cats := append([]Category(nil), categories...)
+	return func() tea.Msg {
+		return searchIndexMsg{items: BuildSearchIndex(cats)}
+	}
+}
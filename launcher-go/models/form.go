@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/ui"
+)
+
+// ParamForm collects the values of a script's @param declarations before it runs
+type ParamForm struct {
+	script Script
+	inputs []textinput.Model
+	errors []error
+	focus  int
+}
+
+// NewParamForm creates a form with one input per declared parameter
+func NewParamForm(script Script) ParamForm {
+	inputs := make([]textinput.Model, len(script.Params))
+	for i, param := range script.Params {
+		ti := textinput.New()
+		ti.CharLimit = 256
+		ti.Width = 40
+		ti.Prompt = "› "
+		ti.Placeholder = paramPlaceholder(param)
+		ti.SetValue(param.Default)
+		inputs[i] = ti
+	}
+
+	form := ParamForm{
+		script: script,
+		inputs: inputs,
+		errors: make([]error, len(script.Params)),
+	}
+	form.setFocus(0)
+	return form
+}
+
+func paramPlaceholder(param ScriptParam) string {
+	switch param.Type {
+	case ParamInt:
+		return "número"
+	case ParamBool:
+		return "s/n"
+	case ParamChoice:
+		return strings.Join(param.Choices, " | ")
+	case ParamPath:
+		return "ruta"
+	}
+	return "texto"
+}
+
+func (f *ParamForm) setFocus(index int) {
+	if len(f.inputs) == 0 {
+		return
+	}
+	if index < 0 {
+		index = len(f.inputs) - 1
+	}
+	if index >= len(f.inputs) {
+		index = 0
+	}
+	f.inputs[f.focus].Blur()
+	f.focus = index
+	f.inputs[f.focus].Focus()
+}
+
+// cycleChoice moves the focused choice/bool parameter to its next or previous value
+func (f *ParamForm) cycleChoice(step int) bool {
+	param := f.script.Params[f.focus]
+	options := param.Choices
+	if param.Type == ParamBool {
+		options = []string{"true", "false"}
+	} else if param.Type != ParamChoice || len(options) == 0 {
+		return false
+	}
+
+	current := strings.TrimSpace(f.inputs[f.focus].Value())
+	next := 0
+	for i, option := range options {
+		if option == current {
+			next = (i + step + len(options)) % len(options)
+			break
+		}
+	}
+	f.inputs[f.focus].SetValue(options[next])
+	f.inputs[f.focus].CursorEnd()
+	return true
+}
+
+// Values returns the raw values typed in each input
+func (f ParamForm) Values() []string {
+	values := make([]string, len(f.inputs))
+	for i, input := range f.inputs {
+		values[i] = input.Value()
+	}
+	return values
+}
+
+// Submit validates the form. On failure the errors are kept for rendering and
+// focus moves to the first invalid field.
+func (f *ParamForm) Submit() (RunOptions, bool) {
+	opts, errs, ok := BuildRunOptions(f.script.Params, f.Values())
+	f.errors = errs
+	if !ok {
+		for i, err := range errs {
+			if err != nil {
+				f.setFocus(i)
+				break
+			}
+		}
+	}
+	return opts, ok
+}
+
+// Update handles a key press. It reports whether the form was submitted
+// (valid) or cancelled.
+func (f *ParamForm) Update(msg tea.KeyMsg) (opts RunOptions, submitted bool, cancelled bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return opts, false, true, nil
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+		return opts, false, false, nil
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+		return opts, false, false, nil
+	case "left", "right":
+		step := 1
+		if msg.String() == "left" {
+			step = -1
+		}
+		if f.cycleChoice(step) {
+			return opts, false, false, nil
+		}
+	case "enter":
+		if f.focus < len(f.inputs)-1 {
+			f.setFocus(f.focus + 1)
+			return opts, false, false, nil
+		}
+		opts, ok := f.Submit()
+		return opts, ok, false, nil
+	case "ctrl+s":
+		opts, ok := f.Submit()
+		return opts, ok, false, nil
+	}
+
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	f.errors[f.focus] = nil
+	return opts, false, false, cmd
+}
+
+// View renders the form
+func (f ParamForm) View() string {
+	content := ui.TitleStyle.Render("⚙ Parámetros: "+f.script.DisplayName()) + "\n"
+	content += ui.DimStyle.Render(f.script.Path) + "\n\n"
+
+	for i, param := range f.script.Params {
+		label := param.Label
+		if param.Required {
+			label += " *"
+		}
+		if i == f.focus {
+			content += ui.SelectedStyle.Render(label) + "\n"
+		} else {
+			content += ui.NormalStyle.Render(label) + "\n"
+		}
+
+		hint := param.Type
+		if param.Env != "" {
+			hint += " · $" + param.Env
+		} else {
+			hint += fmt.Sprintf(" · arg %d", positionalIndex(f.script.Params, i)+len(f.script.Args)+1)
+		}
+		content += "  " + f.inputs[i].View() + "  " + ui.DimStyle.Render(hint) + "\n"
+		if f.errors[i] != nil {
+			content += "  " + ui.ErrorStyle.Render("✗ "+f.errors[i].Error()) + "\n"
+		}
+		content += "\n"
+	}
+
+	content += ui.DimStyle.Render("tab/↑↓: campo  ←/→: opción  enter: siguiente/ejecutar  ctrl+s: ejecutar  esc: cancelar")
+	return content
+}
+
+// positionalIndex returns the position of params[index] among the parameters
+// passed as positional arguments
+func positionalIndex(params []ScriptParam, index int) int {
+	pos := 0
+	for i := 0; i < index; i++ {
+		if params[i].Env == "" {
+			pos++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,396 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lucas/launcher/ui"
+	"github.com/lucas/launcher/utils"
+)
+
+const (
+	// maxHistoryEntries is how many runs are kept in the history file
+	maxHistoryEntries = 200
+	// maxHistoryOutput caps the output stored per run (the tail is kept)
+	maxHistoryOutput = 8 * 1024
+)
+
+// historyMu serializes the writes of the history file: jobs and workflow
+// steps that finish together save their entries from separate goroutines
+var historyMu sync.Mutex
+
+// Sources of a history entry
+const (
+	SourceTUI      = "tui"
+	SourceCLI      = "cli"
+	SourceJob      = "job"
+	SourceWorkflow = "workflow"
+)
+
+// HistoryEntry records one script execution
+type HistoryEntry struct {
+	Script     string        `json:"script"`
+	Name       string        `json:"name"`
+	Extension  string        `json:"extension"`
+	Args       []string      `json:"args,omitempty"`
+	Env        []string      `json:"env,omitempty"` // Names of the env parameters, never their values
+	RunDir     string        `json:"runDir"`
+	WorkDir    string        `json:"workDir"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt time.Time     `json:"finishedAt"`
+	Duration   time.Duration `json:"duration"`
+	ExitCode   int           `json:"exitCode"`
+	Status     string        `json:"status,omitempty"`
+	Output     string        `json:"output,omitempty"`
+	Truncated  bool          `json:"truncated,omitempty"`
+	Source     string        `json:"source"`
+}
+
+// newHistoryEntry builds the record of a finished run
+func newHistoryEntry(script Script, runDir string, opts RunOptions, started, finished time.Time, exitCode int, output, source string) HistoryEntry {
+	entry := HistoryEntry{
+		Script:     script.Path,
+		Name:       script.Name,
+		Extension:  script.Extension,
+		Args:       opts.Args,
+		Env:        envNames(opts.Env),
+		RunDir:     runDir,
+		WorkDir:    resolveWorkingDir(script, runDir),
+		StartedAt:  started,
+		FinishedAt: finished,
+		Duration:   finished.Sub(started),
+		ExitCode:   exitCode,
+		Output:     output,
+		Source:     source,
+	}
+	if len(entry.Output) > maxHistoryOutput {
+		cut := len(entry.Output) - maxHistoryOutput
+		for cut < len(entry.Output) && !utf8.RuneStart(entry.Output[cut]) {
+			cut++
+		}
+		entry.Output = entry.Output[cut:]
+		entry.Truncated = true
+	}
+	return entry
+}
+
+// ScriptRef returns the script of the entry, re-reading its header when the file still exists
+func (e HistoryEntry) ScriptRef() Script {
+	if _, err := os.Stat(e.Script); err == nil {
+		return newScript(e.Script, e.Extension)
+	}
+	return Script{Name: e.Name, Path: e.Script, Extension: e.Extension}
+}
+
+// RunOptions returns the arguments of the recorded run. Values of env
+// parameters are not recorded: they are read again from the current
+// environment, as "launcher run" does.
+func (e HistoryEntry) RunOptions() RunOptions {
+	var env []string
+	for _, name := range e.Env {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return RunOptions{Args: e.Args, Env: env}
+}
+
+// envNames drops the values of KEY=value pairs, which may be secrets
+func envNames(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	names := make([]string, len(env))
+	for i, kv := range env {
+		names[i], _, _ = strings.Cut(kv, "=")
+	}
+	return names
+}
+
+// AppendHistory adds an entry to the history file, trimming it to the most
+// recent maxHistoryEntries runs
+func AppendHistory(historyPath string, entry HistoryEntry) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0755); err != nil {
+		return err
+	}
+
+	entries, err := readHistoryFile(historyPath)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) >= maxHistoryEntries {
+		entries = append(entries[len(entries)-maxHistoryEntries+1:], entry)
+		return writeHistoryFile(historyPath, entries)
+	}
+
+	f, err := os.OpenFile(historyPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// LoadHistory returns the recorded runs, most recent first
+func LoadHistory(historyPath string) ([]HistoryEntry, error) {
+	entries, err := readHistoryFile(historyPath)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// ClearHistory removes every recorded run
+func ClearHistory(historyPath string) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	err := os.Remove(historyPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func readHistoryFile(historyPath string) ([]HistoryEntry, error) {
+	f, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*maxHistoryOutput+64*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue // skip corrupted lines
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func writeHistoryFile(historyPath string, entries []HistoryEntry) error {
+	var sb strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+
+	tmp := historyPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, historyPath)
+}
+
+// formatHistoryLine renders a one-line summary of an entry
+func formatHistoryLine(entry HistoryEntry) string {
+	status := "✓"
+	switch {
+	case entry.Status == RunCancelled:
+		status = "⊘"
+	case entry.Status == RunTimedOut:
+		status = "⏱"
+	case entry.ExitCode != 0:
+		status = fmt.Sprintf("✗ %d", entry.ExitCode)
+	}
+	line := fmt.Sprintf("%s  %-4s %s", entry.StartedAt.Local().Format("2006-01-02 15:04"), status, entry.Name)
+	if len(entry.Args) > 0 {
+		line += " " + strings.Join(entry.Args, " ")
+	}
+	return line + fmt.Sprintf("  (%s)", formatDuration(entry.Duration))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// HistoryCLI implements "launcher history [list [-n N] | show N | rerun [--skip-checks] [--yes] N | clear]"
+func HistoryCLI(args []string) int {
+	historyPath := utils.GetHistoryPath()
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	switch action {
+	case "list", "ls":
+		limit := 20
+		if len(args) == 2 && (args[0] == "-n" || args[0] == "--limit") {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fmt.Fprintln(os.Stderr, "Límite inválido:", args[1])
+				return 2
+			}
+			limit = n
+		} else if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "Uso: launcher history list [-n N]")
+			return 2
+		}
+
+		entries, err := LoadHistory(historyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error leyendo historial:", err)
+			return 1
+		}
+		if len(entries) == 0 {
+			fmt.Println(ui.DimStyle.Render("Historial vacío"))
+			return 0
+		}
+		for i, entry := range entries {
+			if i >= limit {
+				break
+			}
+			fmt.Printf("  [%d] %s\n", i+1, formatHistoryLine(entry))
+		}
+		return 0
+
+	case "show":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Uso: launcher history show <N>")
+			return 2
+		}
+		entry, err := historyEntryAt(historyPath, args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		fmt.Println(formatHistoryLine(entry))
+		fmt.Printf("Script:     %s\n", entry.Script)
+		fmt.Printf("Directorio: %s\n", entry.WorkDir)
+		if len(entry.Env) > 0 {
+			fmt.Printf("Entorno:    %s\n", strings.Join(entry.Env, " "))
+		}
+		fmt.Printf("Exit code:  %d\n", entry.ExitCode)
+		if entry.Status != "" {
+			fmt.Printf("Estado:     %s\n", entry.Status)
+		}
+		if entry.Output != "" {
+			fmt.Println()
+			if entry.Truncated {
+				fmt.Println("... (salida truncada) ...")
+			}
+			fmt.Print(entry.Output)
+		}
+		return 0
+
+	case "rerun":
+		return rerunHistoryCLI(historyPath, args)
+
+	case "clear":
+		if err := ClearHistory(historyPath); err != nil {
+			fmt.Fprintln(os.Stderr, "Error borrando historial:", err)
+			return 1
+		}
+		fmt.Println("Historial borrado")
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Acción desconocida: %s\n", action)
+	fmt.Fprintln(os.Stderr, "Uso: launcher history [list [-n N] | show N | rerun [--skip-checks] [--yes] N | clear]")
+	return 2
+}
+
+// rerunHistoryCLI implements "launcher history rerun [--skip-checks] [--yes] N"
+// with the same checks as "launcher run": exit 3 when requirements are not
+// met and 4 when the confirmation is refused.
+func rerunHistoryCLI(historyPath string, args []string) int {
+	var numArg string
+	skipChecks := false
+	assumeYes := false
+	usage := "Uso: launcher history rerun [--skip-checks] [--yes] <N>"
+	for _, arg := range args {
+		switch {
+		case arg == "--skip-checks":
+			skipChecks = true
+		case arg == "--yes" || arg == "-y":
+			assumeYes = true
+		case numArg != "":
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		default:
+			numArg = arg
+		}
+	}
+	if numArg == "" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	entry, err := historyEntryAt(historyPath, numArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	script := entry.ScriptRef()
+	categories, err := ScanCategories(ResolveScriptRoots(FindRootDir(), entry.RunDir))
+	if err == nil {
+		script = withEnvContext(categories, script)
+	}
+	if !skipChecks && !preflightCLI(script) {
+		return 3
+	}
+	opts := entry.RunOptions()
+	if !assumeYes && !confirmCLI(script, scriptLogicalPath(categories, script), entry.RunDir, opts) {
+		return 4
+	}
+
+	fmt.Println(ui.DimStyle.Render("Re-ejecutando: " + entry.Script))
+	return runCLIScript(script, entry.RunDir, opts, historyPath)
+}
+
+func historyEntryAt(historyPath, numArg string) (HistoryEntry, error) {
+	num, err := strconv.Atoi(numArg)
+	if err != nil || num <= 0 {
+		return HistoryEntry{}, fmt.Errorf("número de entrada inválido: %s", numArg)
+	}
+	entries, err := LoadHistory(historyPath)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if num > len(entries) {
+		return HistoryEntry{}, fmt.Errorf("la entrada %d no existe (hay %d)", num, len(entries))
+	}
+	return entries[num-1], nil
+}
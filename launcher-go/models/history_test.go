@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppendHistoryConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := 0; i < maxHistoryEntries-50; i++ {
+		if err := AppendHistory(path, HistoryEntry{Name: fmt.Sprintf("old%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Enough runs to cross the limit, so some appends rewrite the file
+	const runs = 100
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := AppendHistory(path, HistoryEntry{Name: fmt.Sprintf("job%d", i)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxHistoryEntries {
+		t.Fatalf("len(entries) = %d, want %d", len(entries), maxHistoryEntries)
+	}
+	jobs := 0
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name, "job") {
+			jobs++
+		}
+	}
+	if jobs != runs {
+		t.Errorf("%d of %d concurrent entries were kept", jobs, runs)
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/ui"
+)
+
+// openHistory loads the history file and switches to HistoryView once loaded.
+// Callers set historyReturn to the view esc should go back to.
+func (m *Model) openHistory() tea.Cmd {
+	return loadHistory(m.historyPath)
+}
+
+// rerunHistory runs a recorded entry again with the same args, env and run
+// dir, through the same pre-flight report and confirmation as a new run
+func (m *Model) rerunHistory(entry HistoryEntry) tea.Cmd {
+	script := entry.ScriptRef()
+	if m.openPreflight(script) {
+		m.currentScript = script
+		m.preflightRerun = &entry
+		return nil
+	}
+	return m.runScriptIn(script, entry.RunDir, entry.RunOptions())
+}
+
+// updateHistoryView handles keys in HistoryView. Keys it does not use are
+// left to the global handler (command mode, quit).
+func (m *Model) updateHistoryView(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc", "0", ".":
+		m.state = m.historyReturn
+		return nil, true
+	case "up", "k":
+		if m.historyIndex > 0 {
+			m.historyIndex--
+		}
+		return nil, true
+	case "down", "j":
+		if m.historyIndex < len(m.history)-1 {
+			m.historyIndex++
+		}
+		return nil, true
+	case "enter", "r":
+		if m.historyIndex < len(m.history) {
+			return m.rerunHistory(m.history[m.historyIndex]), true
+		}
+		return nil, true
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		num := int(msg.String()[0]-'0') - 1
+		if num < len(m.history) {
+			m.historyIndex = num
+			return m.rerunHistory(m.history[num]), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m Model) renderHistoryView() string {
+	content := ui.RenderBreadcrumb([]string{"Inicio", "Historial"}, m.runDir)
+	content += ui.TitleStyle.Render("🕘 Historial de ejecuciones") + "\n"
+
+	if len(m.history) == 0 {
+		content += ui.DimStyle.Render("Todavía no se ha ejecutado ningún script") + "\n"
+	} else {
+		visible := m.height - 14
+		if visible < 5 {
+			visible = 5
+		}
+		start := 0
+		if m.historyIndex >= visible {
+			start = m.historyIndex - visible + 1
+		}
+		end := start + visible
+		if end > len(m.history) {
+			end = len(m.history)
+		}
+
+		for i := start; i < end; i++ {
+			entry := m.history[i]
+			prefix := fmt.Sprintf("  [%d] ", i+1)
+			line := formatHistoryLine(entry)
+			switch {
+			case i == m.historyIndex:
+				content += ui.SelectedStyle.Render(prefix) + ui.SelectedExecutableStyle.Render(line) + "\n"
+			case entry.ExitCode != 0:
+				content += ui.NormalStyle.Render(prefix) + ui.ErrorStyle.Render(line) + "\n"
+			default:
+				content += ui.NormalStyle.Render(prefix) + ui.ExecutableStyle.Render(line) + "\n"
+			}
+		}
+		if len(m.history) > visible {
+			content += ui.DimStyle.Render(fmt.Sprintf("  [%d-%d de %d]", start+1, end, len(m.history))) + "\n"
+		}
+
+		selected := m.history[m.historyIndex]
+		content += "\n" + ui.DimStyle.Render("      "+selected.Script) + "\n"
+		content += ui.DimStyle.Render("      dir: "+selected.WorkDir) + "\n"
+		if len(selected.Env) > 0 {
+			content += ui.DimStyle.Render("      env: "+strings.Join(selected.Env, " ")) + "\n"
+		}
+	}
+
+	content += "\n" + ui.DimStyle.Render("↑↓/j/k: navegar  enter/r/número: re-ejecutar  /: buscar  :: terminal  ./0/esc: volver  q: salir")
+
+	if m.commandMode.active {
+		content += m.commandMode.View()
+	}
+
+	return content
+}
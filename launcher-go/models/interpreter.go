@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/interpreter"
+	"github.com/lucas/launcher/ui"
+)
+
+// interpreters returns the registry shared by the scanner and the executor:
+// the built-ins plus the interpreters section of the config file
+func interpreters() *interpreter.Registry {
+	return interpreter.NewRegistry(config.Current().Interpreters)
+}
+
+// scriptInterpreter returns the interpreter that runs script, the one chosen
+// when it was scanned or, for scripts built by hand, the one of its file
+func scriptInterpreter(script Script) (interpreter.Interpreter, error) {
+	registry := interpreters()
+	if script.Interpreter != "" {
+		if in, ok := registry.Lookup(script.Interpreter); ok {
+			return in, nil
+		}
+	}
+	if in, ok := registry.Resolve(script.Path); ok {
+		return in, nil
+	}
+	return interpreter.Interpreter{}, fmt.Errorf("no hay intérprete para %s", script.Name)
+}
+
+// interpreterLabel returns the interpreter shown next to a script in the
+// list, prefixed with #! when the script is executed directly
+func interpreterLabel(script Script) string {
+	if script.Interpreter == "" {
+		return ""
+	}
+	if script.Direct {
+		return "#!" + script.Interpreter
+	}
+	return script.Interpreter
+}
+
+// describeInterpreters lists the registry with the availability of each entry
+func describeInterpreters() string {
+	var b strings.Builder
+	for _, in := range interpreters().All() {
+		mark := ui.SuccessStyle.Render("✓")
+		program, err := in.Program()
+		if err != nil {
+			mark = ui.ErrorStyle.Render("✗")
+			program = "no encontrado"
+		}
+		matches := append(append([]string(nil), in.Extensions...), prefixAll("#!", in.Shebangs)...)
+		fmt.Fprintf(&b, "  %s %-8s %-40s %s\n", mark, in.Name, strings.Join(matches, " "), ui.DimStyle.Render(program))
+	}
+	return b.String()
+}
+
+func prefixAll(prefix string, items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, prefix+item)
+	}
+	return out
+}
+
+// InterpretersCLI implements "launcher interpreters"
+func InterpretersCLI(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Uso: launcher interpreters")
+		return 2
+	}
+	fmt.Println("Intérpretes (✓ disponible en el PATH):")
+	fmt.Print(describeInterpreters())
+	fmt.Println()
+	fmt.Println("Extensiones listadas:", strings.Join(config.Current().Extensions, ", "))
+	return 0
+}
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/ui"
+)
+
+// Job is a script started in the background (key b or @mode background).
+// It runs captured like @mode capture, without a terminal or stdin, while
+// the launcher stays usable; JobsView lists it until it is removed.
+type Job struct {
+	ID       int
+	script   Script
+	run      *scriptRun // nil when the script could not be started
+	done     bool
+	exitCode int
+	status   string // RunOK, RunFailed, RunCancelled or RunTimedOut once done
+	output   string // Final output once done
+	duration time.Duration
+}
+
+type jobFinishedMsg struct {
+	id       int
+	exitCode int
+	output   string
+	entry    HistoryEntry
+}
+
+type jobsTickMsg time.Time
+
+// jobsTick refreshes JobsView while some job is running
+func jobsTick() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+		return jobsTickMsg(t)
+	})
+}
+
+// startJob runs a script in the background. Only an explicit @timeout
+// applies: the config timeout is meant for scripts the user waits for.
+func (m *Model) startJob(script Script, runDir string, opts RunOptions) tea.Cmd {
+	m.nextJobID++
+	job := &Job{ID: m.nextJobID, script: script}
+	m.jobs = append(m.jobs, job)
+
+	run, err := newScriptRun(script, runDir, opts)
+	if err != nil {
+		return func() tea.Msg {
+			now := time.Now()
+			entry := newHistoryEntry(script, runDir, opts, now, now, 1, err.Error(), SourceJob)
+			entry.Status = RunFailed
+			return jobFinishedMsg{id: job.ID, exitCode: 1, output: err.Error(), entry: entry}
+		}
+	}
+	run.timeout = script.Timeout
+	run.SetStdout(io.Discard)
+	run.SetStderr(io.Discard)
+	job.run = run
+
+	return func() tea.Msg {
+		exitCode, output := run.Result(run.Run())
+		return jobFinishedMsg{id: job.ID, exitCode: exitCode, output: output, entry: run.HistoryEntry(exitCode, output, SourceJob)}
+	}
+}
+
+// finishJob records the result of a job and saves it to the history
+func (m *Model) finishJob(msg jobFinishedMsg) tea.Cmd {
+	for _, job := range m.jobs {
+		if job.ID == msg.id {
+			job.done = true
+			job.exitCode = msg.exitCode
+			job.status = msg.entry.Status
+			job.output = msg.output
+			job.duration = msg.entry.Duration
+		}
+	}
+	save := saveHistory(m.historyPath, msg.entry)
+	if m.quitPending && m.runningJobs() == 0 {
+		return tea.Sequence(save, tea.Quit)
+	}
+	return save
+}
+
+// runningJobs counts the jobs that have not finished yet
+func (m *Model) runningJobs() int {
+	count := 0
+	for _, job := range m.jobs {
+		if !job.done {
+			count++
+		}
+	}
+	return count
+}
+
+// openJobs switches to JobsView
+func (m *Model) openJobs() tea.Cmd {
+	if m.state != JobsView {
+		m.jobsReturn = m.state
+	}
+	m.state = JobsView
+	if m.jobIndex >= len(m.jobs) {
+		m.jobIndex = len(m.jobs) - 1
+	}
+	if m.jobIndex < 0 {
+		m.jobIndex = 0
+	}
+	if m.jobsTicking {
+		return nil
+	}
+	m.jobsTicking = true
+	return jobsTick()
+}
+
+// quit exits the launcher. With jobs still running it first shows them in
+// JobsView; quitting again from there stops them.
+func (m *Model) quit() tea.Cmd {
+	if m.runningJobs() == 0 {
+		return tea.Quit
+	}
+	if m.quitPending && m.state == JobsView {
+		return m.stopJobsAndQuit()
+	}
+	m.quitPending = true
+	return m.openJobs()
+}
+
+// stopJobsAndQuit cancels every running job and quits once they exited (or
+// were killed after kill_grace), so no script outlives the launcher
+func (m *Model) stopJobsAndQuit() tea.Cmd {
+	var runs []*scriptRun
+	for _, job := range m.jobs {
+		if !job.done && job.run != nil {
+			job.run.Cancel()
+			runs = append(runs, job.run)
+		}
+	}
+	grace := time.Duration(config.Current().KillGrace)
+	return func() tea.Msg {
+		deadline := time.After(grace + time.Second)
+		for _, run := range runs {
+			select {
+			case <-run.done:
+			case <-deadline:
+				return tea.QuitMsg{}
+			}
+		}
+		return tea.QuitMsg{}
+	}
+}
+
+// updateJobsView handles keys in JobsView. Keys it does not use are left to
+// the global handler (command mode, quit).
+func (m *Model) updateJobsView(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc", "0", ".":
+		m.quitPending = false
+		m.state = m.jobsReturn
+		return nil, true
+	case "up", "k":
+		if m.jobIndex > 0 {
+			m.jobIndex--
+		}
+		return nil, true
+	case "down", "j":
+		if m.jobIndex < len(m.jobs)-1 {
+			m.jobIndex++
+		}
+		return nil, true
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if num := int(msg.String()[0]-'0') - 1; num < len(m.jobs) {
+			m.jobIndex = num
+		}
+		return nil, true
+	}
+
+	if m.jobIndex >= len(m.jobs) {
+		return nil, false
+	}
+	job := m.jobs[m.jobIndex]
+	switch msg.String() {
+	case "x":
+		// A second x kills the job without waiting for kill_grace
+		if !job.done && job.run != nil {
+			job.run.Cancel()
+		}
+		return nil, true
+	case "enter":
+		if job.done {
+			m.showJobResult(job)
+		}
+		return nil, true
+	case "d":
+		if job.done {
+			m.jobs = append(m.jobs[:m.jobIndex], m.jobs[m.jobIndex+1:]...)
+			if m.jobIndex >= len(m.jobs) && m.jobIndex > 0 {
+				m.jobIndex--
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// showJobResult opens the full output of a finished job in ResultView
+func (m *Model) showJobResult(job *Job) {
+	m.currentScript = job.script
+	m.executionResult = job.exitCode
+	m.executionStatus = job.status
+	m.executionTime = job.duration
+	m.executionOutput = job.output
+	m.showResult()
+	m.returnState = JobsView
+	m.state = ResultView
+}
+
+// Elapsed returns how long the job has been running, or ran once done
+func (j *Job) Elapsed() time.Duration {
+	if j.done {
+		return j.duration
+	}
+	if j.run == nil {
+		return 0
+	}
+	return j.run.Elapsed()
+}
+
+// statusLabel describes the state of a job for JobsView
+func (j *Job) statusLabel() string {
+	if !j.done {
+		if j.run != nil && j.run.StopReason() != "" {
+			return ui.WarningStyle.Render("deteniendo...")
+		}
+		return ui.SuccessStyle.Render("● en ejecución")
+	}
+	switch j.status {
+	case RunOK:
+		return ui.SuccessStyle.Render("✓ terminado")
+	case RunCancelled:
+		return ui.ErrorStyle.Render(fmt.Sprintf("⊘ detenido (exit %d)", j.exitCode))
+	case RunTimedOut:
+		return ui.ErrorStyle.Render("⏱ tiempo agotado")
+	}
+	return ui.ErrorStyle.Render(fmt.Sprintf("✗ falló (exit %d)", j.exitCode))
+}
+
+// jobsSummary is the line shown under the lists while there are jobs
+func (m Model) jobsSummary() string {
+	if len(m.jobs) == 0 {
+		return ""
+	}
+	running := m.runningJobs()
+	return ui.DimStyle.Render(fmt.Sprintf("⚙ Jobs: %d en ejecución, %d terminado(s)  J: ver", running, len(m.jobs)-running)) + "\n"
+}
+
+func (m Model) renderJobsView() string {
+	content := ui.RenderBreadcrumb([]string{"Inicio", "Jobs"}, m.runDir)
+	content += ui.TitleStyle.Render("⚙ Scripts en segundo plano") + "\n"
+
+	if m.quitPending && m.runningJobs() > 0 {
+		content += ui.WarningStyle.Render(fmt.Sprintf("⚠ Hay %d job(s) en ejecución. q: detenerlos y salir  esc: volver", m.runningJobs())) + "\n"
+	}
+	content += "\n"
+
+	if len(m.jobs) == 0 {
+		content += ui.DimStyle.Render("No hay scripts en segundo plano (b en la lista de scripts)") + "\n"
+		content += "\n" + ui.DimStyle.Render("/: buscar  esc/./0: volver  q: salir")
+		return content
+	}
+
+	for i, job := range m.jobs {
+		prefix := fmt.Sprintf("  [%d] ", i+1)
+		pid := "-"
+		if job.run != nil && job.run.PID() != 0 {
+			pid = fmt.Sprint(job.run.PID())
+		}
+		line := fmt.Sprintf("%-28s PID %-7s %8s  ", job.script.DisplayName(), pid, formatDuration(job.Elapsed()))
+		if i == m.jobIndex {
+			content += ui.SelectedStyle.Render(prefix) + ui.SelectedExecutableStyle.Render(line) + job.statusLabel() + "\n"
+		} else {
+			content += ui.NormalStyle.Render(prefix) + ui.ExecutableStyle.Render(line) + job.statusLabel() + "\n"
+		}
+	}
+
+	if m.jobIndex < len(m.jobs) {
+		job := m.jobs[m.jobIndex]
+		content += "\n" + ui.DimStyle.Render("── Salida de "+job.script.DisplayName()+" ──") + "\n"
+
+		visible := m.height - len(m.jobs) - 12
+		if visible < 5 {
+			visible = 5
+		}
+		var lines []string
+		if job.run != nil {
+			lines = job.run.output.Tail(visible)
+		} else {
+			lines = strings.Split(strings.TrimRight(job.output, "\n"), "\n")
+		}
+		if len(lines) == 0 {
+			content += ui.DimStyle.Render("(Sin salida)") + "\n"
+		}
+		for _, line := range lines {
+			content += line + "\x1b[0m\n"
+		}
+	}
+
+	content += "\n" + ui.DimStyle.Render("↑↓/j/k/1-9: elegir  x: detener (de nuevo: forzar)  enter: salida completa  d: quitar terminado  /: buscar  esc/./0: volver  q: salir")
+
+	if m.commandMode.active {
+		content += m.commandMode.View()
+	}
+	return content
+}
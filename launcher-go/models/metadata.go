@@ -0,0 +1,270 @@
+package models
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHeaderLines limits how far into a script the header block is searched
+const maxHeaderLines = 60
+
+// ScriptMeta holds the metadata declared in a script's header block.
+//
+// The header is the leading comment block of the script (after the shebang
+// or "@echo off"), where each "@key value" line sets one field:
+//
+//	#!/bin/bash
+//	# @name        Inicializar módulo Go
+//	# @description Crea una carpeta module/ con estructura básica
+//	# @tags        go, init
+//	# @requires    go, git
+//	# @requires-env GITHUB_TOKEN
+//	# @requires-os  ubuntu>=22.04, debian>=12
+//	# @requires-launcher >=v0.5.0
+//	# @sudo
+//	# @confirm
+//	# @dangerous
+//	# @timeout     5m
+//	# @mode        capture
+//	# @cwd         script
+//	# @args        --verbose "mi proyecto"
+//	# @param       MODULE_NAME "Nombre del módulo" default=module env
+//
+// PowerShell scripts may use "#" lines or a "<# ... #>" block, batch files
+// use "REM" or "::" lines.
+type ScriptMeta struct {
+	Title       string
+	Description string
+	Tags        []string
+	Requires    []string
+	RequiresEnv []string
+	RequiresOS  []string
+	MinLauncher string
+	Root        bool // Must run as root / administrator
+	Sudo        bool // Needs root or the sudo command
+	Confirm     bool
+	Dangerous   bool // Ask to type the script name before running
+	Timeout     time.Duration
+	Mode        string
+	Cwd         string
+	Args        []string
+	Params      []ScriptParam
+
+	// summary is the first plain comment of the header, used as description
+	// for scripts that do not declare @description.
+	summary string
+}
+
+// ParseScriptMeta reads the header block of a script
+func ParseScriptMeta(scriptPath string) (ScriptMeta, error) {
+	var meta ScriptMeta
+
+	file, err := os.Open(scriptPath)
+	if err != nil {
+		return meta, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	inBlock := false
+	lineCount := 0
+
+	for scanner.Scan() && lineCount < maxHeaderLines {
+		line := strings.TrimSpace(scanner.Text())
+		lineCount++
+
+		if lineCount == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		// Skip shebang, empty lines and the usual batch preamble
+		if strings.HasPrefix(line, "#!") || line == "" || strings.EqualFold(line, "@echo off") {
+			continue
+		}
+
+		text, ok := headerCommentText(line, &inBlock)
+		if !ok {
+			break
+		}
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "@") {
+			key, value := splitHeaderDirective(text)
+			meta.apply(key, value)
+			continue
+		}
+
+		if meta.summary == "" {
+			meta.summary = text
+		}
+	}
+
+	return meta, scanner.Err()
+}
+
+// headerCommentText strips the comment marker from a header line. It returns
+// false once the line is no longer part of the leading comment block.
+func headerCommentText(line string, inBlock *bool) (string, bool) {
+	if *inBlock {
+		if idx := strings.Index(line, "#>"); idx >= 0 {
+			*inBlock = false
+			line = line[:idx]
+		}
+		return strings.TrimSpace(strings.TrimLeft(line, "#")), true
+	}
+
+	upper := strings.ToUpper(line)
+	switch {
+	case strings.HasPrefix(line, "<#"):
+		line = strings.TrimPrefix(line, "<#")
+		if idx := strings.Index(line, "#>"); idx >= 0 {
+			line = line[:idx]
+		} else {
+			*inBlock = true
+		}
+		return strings.TrimSpace(line), true
+	case strings.HasPrefix(line, "#"):
+		return strings.TrimSpace(strings.TrimLeft(line, "#")), true
+	case strings.HasPrefix(line, "//"):
+		return strings.TrimSpace(strings.TrimLeft(line, "/")), true
+	case strings.HasPrefix(line, "::"):
+		return strings.TrimSpace(strings.TrimPrefix(line, "::")), true
+	case upper == "REM" || upper == "@REM":
+		return "", true
+	case strings.HasPrefix(upper, "REM "):
+		return strings.TrimSpace(line[4:]), true
+	case strings.HasPrefix(upper, "@REM "):
+		return strings.TrimSpace(line[5:]), true
+	}
+
+	return "", false
+}
+
+func splitHeaderDirective(text string) (string, string) {
+	text = strings.TrimPrefix(text, "@")
+	idx := strings.IndexFunc(text, func(r rune) bool { return r == ' ' || r == '\t' || r == ':' })
+	if idx == -1 {
+		return strings.ToLower(text), ""
+	}
+	key := strings.ToLower(text[:idx])
+	value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text[idx:]), ":"))
+	return key, value
+}
+
+func (m *ScriptMeta) apply(key, value string) {
+	switch key {
+	case "name", "title":
+		m.Title = value
+	case "description", "desc":
+		m.Description = value
+	case "tags", "tag":
+		m.Tags = append(m.Tags, splitList(value)...)
+	case "requires", "require":
+		m.Requires = append(m.Requires, splitList(value)...)
+	case "requires-env":
+		m.RequiresEnv = append(m.RequiresEnv, splitList(value)...)
+	case "requires-os":
+		m.RequiresOS = append(m.RequiresOS, splitList(value)...)
+	case "requires-launcher":
+		m.MinLauncher = strings.TrimSpace(strings.TrimPrefix(value, ">="))
+	case "root", "admin":
+		m.Root = parseHeaderBool(value)
+	case "sudo":
+		m.Sudo = parseHeaderBool(value)
+	case "confirm":
+		m.Confirm = parseHeaderBool(value)
+	case "dangerous", "destructive":
+		m.Dangerous = parseHeaderBool(value)
+	case "timeout":
+		if d, ok := parseHeaderDuration(value); ok {
+			m.Timeout = d
+		}
+	case "mode":
+		m.Mode = strings.ToLower(value)
+	case "cwd":
+		m.Cwd = value
+	case "args":
+		m.Args = append(m.Args, splitArgs(value)...)
+	case "param":
+		if param, ok := parseParamDirective(value); ok {
+			m.Params = append(m.Params, param)
+		}
+	}
+}
+
+// splitList splits a comma or space separated header value
+func splitList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	items := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			items = append(items, field)
+		}
+	}
+	return items
+}
+
+func parseHeaderBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "true", "yes", "si", "sí", "1", "on":
+		return true
+	}
+	return false
+}
+
+// parseHeaderDuration accepts Go durations ("90s", "5m") or plain seconds
+func parseHeaderDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, true
+	}
+	return 0, false
+}
+
+// splitArgs splits a command line into arguments honouring single and double quotes
+func splitArgs(value string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	for _, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
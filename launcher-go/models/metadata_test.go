@@ -0,0 +1,129 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeScript(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseScriptMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    ScriptMeta
+	}{
+		{
+			name: "bash",
+			file: "init.sh",
+			content: "#!/bin/bash\n" +
+				"# @name        Inicializar módulo Go\n" +
+				"# @description Crea una carpeta module/\n" +
+				"# @tags        go, init\n" +
+				"# @tags        tools\n" +
+				"# @requires    go git\n" +
+				"# @confirm\n" +
+				"# @timeout     5m\n" +
+				"# @cwd         script\n" +
+				"# @args        --verbose \"mi proyecto\"\n" +
+				"\n" +
+				"echo hola\n" +
+				"# @name no es cabecera\n",
+			want: ScriptMeta{
+				Title:       "Inicializar módulo Go",
+				Description: "Crea una carpeta module/",
+				Tags:        []string{"go", "init", "tools"},
+				Requires:    []string{"go", "git"},
+				Confirm:     true,
+				Timeout:     5 * time.Minute,
+				Cwd:         "script",
+				Args:        []string{"--verbose", "mi proyecto"},
+			},
+		},
+		{
+			name:    "summary and key with colon",
+			file:    "a.sh",
+			content: "\ufeff#!/bin/sh\n# Limpia la caché\n# Segunda línea\n# @Timeout: 90\n# @confirm no\n",
+			want:    ScriptMeta{Timeout: 90 * time.Second, summary: "Limpia la caché"},
+		},
+		{
+			name:    "powershell block",
+			file:    "a.ps1",
+			content: "<#\n  @name Instalar\n  @tags win\n#>\nWrite-Host hola\n",
+			want:    ScriptMeta{Title: "Instalar", Tags: []string{"win"}},
+		},
+		{
+			name:    "batch",
+			file:    "a.bat",
+			content: "@echo off\r\nREM @name Compilar\r\n:: @description Compila todo\r\n@REM\r\necho hola\r\n",
+			want:    ScriptMeta{Title: "Compilar", Description: "Compila todo"},
+		},
+		{
+			name:    "no header",
+			file:    "a.py",
+			content: "import os\n# @name tarde\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScriptMeta(writeScript(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseScriptMeta =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		``:                      nil,
+		`a b  c`:                {"a", "b", "c"},
+		`--name "mi proyecto"`:  {"--name", "mi proyecto"},
+		`'a "b"' c`:             {`a "b"`, "c"},
+		`x="1 2"`:               {"x=1 2"},
+		`""`:                    {""},
+		"tab\tseparated":        {"tab", "separated"},
+		`"sin cerrar y espacio`: {"sin cerrar y espacio"},
+	}
+	for in, want := range tests {
+		if got := splitArgs(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseHeaderDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"90", 90 * time.Second, true},
+		{"5m", 5 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"-5", 0, false},
+		{"cinco", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHeaderDuration(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseHeaderDuration(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/ui"
+)
+
+// nonColorSequence matches the CSI sequences that are not colours (cursor
+// movement, line erase...), dropped because they break the layout
+var nonColorSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-ln-z]`)
+
+// OutputViewer shows the output of a run in ResultView. Colours are kept,
+// lines wrap to the terminal (or output_width) or scroll sideways, and the
+// text can be searched with / and n/N, saved to a file or copied with OSC 52.
+type OutputViewer struct {
+	lines     []string // Output lines with colours, other control sequences removed
+	plain     []string // The same lines without escape sequences
+	wrap      bool
+	offset    int // First visible row
+	column    int // Horizontal scroll when wrap is off
+	width     int
+	height    int
+	searching bool
+	input     textinput.Model
+	query     string
+	matches   []int // Lines containing query
+	match     int   // Current entry of matches
+	notice    string
+}
+
+// viewRow is one screen row of the output: a whole line or a wrapped piece
+type viewRow struct {
+	line int
+	text string
+}
+
+// NewOutputViewer prepares the viewer for an output
+func NewOutputViewer(output string) OutputViewer {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.CharLimit = 100
+	ti.Width = 40
+
+	v := OutputViewer{wrap: true, input: ti, width: 80, height: 10}
+	if output == "" {
+		return v
+	}
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		// Progress bars redraw the line after \r: keep what was left on screen
+		line = strings.TrimRight(line, "\r")
+		if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+		line = nonColorSequence.ReplaceAllString(line, "")
+		line = strings.ReplaceAll(line, "\t", "    ")
+		v.lines = append(v.lines, line)
+		v.plain = append(v.plain, ansi.Strip(line))
+	}
+	return v
+}
+
+// SetSize sets the area available for the output
+func (v *OutputViewer) SetSize(width, height int) {
+	if limit := config.Current().OutputWidth; limit > 0 && limit < width {
+		width = limit
+	}
+	if width < 20 {
+		width = 20
+	}
+	if height < 3 {
+		height = 3
+	}
+	v.width, v.height = width, height
+	v.clamp()
+}
+
+// Searching reports whether the search prompt has the keyboard
+func (v OutputViewer) Searching() bool {
+	return v.searching
+}
+
+// rows lays out the lines for the current width and wrap setting
+func (v OutputViewer) rows() []viewRow {
+	rows := make([]viewRow, 0, len(v.lines))
+	for i, line := range v.lines {
+		if !v.wrap || ansi.StringWidth(line) <= v.width {
+			rows = append(rows, viewRow{line: i, text: line})
+			continue
+		}
+		for _, piece := range strings.Split(ansi.Hardwrap(line, v.width, true), "\n") {
+			rows = append(rows, viewRow{line: i, text: piece})
+		}
+	}
+	return rows
+}
+
+func (v *OutputViewer) maxOffset() int {
+	max := len(v.rows()) - v.height
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+func (v *OutputViewer) clamp() {
+	if max := v.maxOffset(); v.offset > max {
+		v.offset = max
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+	if v.column < 0 {
+		v.column = 0
+	}
+}
+
+// ScrollBy moves the view n rows down (up when negative)
+func (v *OutputViewer) ScrollBy(n int) {
+	v.offset += n
+	v.clamp()
+}
+
+// Update handles a key. handled is false for keys the viewer does not use,
+// which are left to ResultView (back, quit).
+func (v *OutputViewer) Update(msg tea.KeyMsg) (cmd tea.Cmd, handled bool) {
+	if v.searching {
+		switch msg.String() {
+		case "esc":
+			v.searching = false
+			v.input.Blur()
+		case "enter":
+			v.searching = false
+			v.input.Blur()
+			v.search(v.input.Value())
+		default:
+			v.input, cmd = v.input.Update(msg)
+		}
+		return cmd, true
+	}
+
+	v.notice = ""
+	switch msg.String() {
+	case "up", "k":
+		v.ScrollBy(-1)
+	case "down", "j":
+		v.ScrollBy(1)
+	case "pgup", "ctrl+b":
+		v.ScrollBy(-v.height)
+	case "pgdown", "ctrl+f", " ":
+		v.ScrollBy(v.height)
+	case "ctrl+u":
+		v.ScrollBy(-v.height / 2)
+	case "ctrl+d":
+		v.ScrollBy(v.height / 2)
+	case "home", "g":
+		v.offset = 0
+	case "end", "G":
+		v.offset = v.maxOffset()
+	case "left", "h":
+		if !v.wrap {
+			v.column -= 8
+			v.clamp()
+		}
+	case "right", "l":
+		if !v.wrap {
+			v.column += 8
+		}
+	case "w":
+		v.toggleWrap()
+	case "/":
+		v.searching = true
+		v.input.SetValue(v.query)
+		v.input.CursorEnd()
+		return v.input.Focus(), true
+	case "n":
+		v.nextMatch(1)
+	case "N":
+		v.nextMatch(-1)
+	case "c":
+		return v.copyToClipboard(), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// toggleWrap switches between wrapped and sideways scrolled lines, keeping
+// the first visible line in place
+func (v *OutputViewer) toggleWrap() {
+	line := 0
+	if rows := v.rows(); v.offset < len(rows) {
+		line = rows[v.offset].line
+	}
+	v.wrap = !v.wrap
+	v.column = 0
+	v.showLine(line, false)
+	if v.wrap {
+		v.notice = "Ajuste de línea activado"
+	} else {
+		v.notice = "Ajuste de línea desactivado (←→ para desplazar)"
+	}
+}
+
+// showLine scrolls so that line is visible, in the middle of the view when
+// center is set
+func (v *OutputViewer) showLine(line int, center bool) {
+	for i, row := range v.rows() {
+		if row.line == line {
+			v.offset = i
+			if center {
+				v.offset -= v.height / 2
+			}
+			break
+		}
+	}
+	v.clamp()
+}
+
+// search finds the lines containing query, ignoring case unless it has
+// capitals, and jumps to the first one below the top of the view
+func (v *OutputViewer) search(query string) {
+	v.query = query
+	v.matches = nil
+	v.match = 0
+	if query == "" {
+		return
+	}
+	for i, line := range v.plain {
+		if start, _ := findMatch(line, query); start >= 0 {
+			v.matches = append(v.matches, i)
+		}
+	}
+	if len(v.matches) == 0 {
+		v.notice = "Sin coincidencias para " + query
+		return
+	}
+
+	top := 0
+	if rows := v.rows(); v.offset < len(rows) {
+		top = rows[v.offset].line
+	}
+	for i, line := range v.matches {
+		if line >= top {
+			v.match = i
+			break
+		}
+	}
+	v.jumpToMatch()
+}
+
+// nextMatch moves to the next (dir 1) or previous (dir -1) match
+func (v *OutputViewer) nextMatch(dir int) {
+	if len(v.matches) == 0 {
+		if v.query != "" {
+			v.notice = "Sin coincidencias para " + v.query
+		}
+		return
+	}
+	v.match = (v.match + dir + len(v.matches)) % len(v.matches)
+	v.jumpToMatch()
+}
+
+func (v *OutputViewer) jumpToMatch() {
+	line := v.matches[v.match]
+	v.showLine(line, true)
+	if !v.wrap {
+		start, _ := findMatch(v.plain[line], v.query)
+		col := ansi.StringWidth(v.plain[line][:start])
+		if col < v.column || col >= v.column+v.width {
+			v.column = col - v.width/4
+			v.clamp()
+		}
+	}
+	v.notice = fmt.Sprintf("Coincidencia %d de %d", v.match+1, len(v.matches))
+}
+
+// findMatch returns the byte range of the first occurrence of query in text,
+// or -1, -1. Smart case: a query without upper case letters ignores case,
+// compared rune by rune on text itself so the range is always valid in it
+// (lowercasing can change the length, e.g. "İ").
+func findMatch(text, query string) (start, end int) {
+	if query == "" {
+		return -1, -1
+	}
+	if strings.IndexFunc(query, unicode.IsUpper) >= 0 {
+		if idx := strings.Index(text, query); idx >= 0 {
+			return idx, idx + len(query)
+		}
+		return -1, -1
+	}
+	for i := 0; i < len(text); {
+		if n, ok := foldPrefix(text[i:], query); ok {
+			return i, i + n
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return -1, -1
+}
+
+// foldPrefix reports whether text starts with query ignoring case, and the
+// length in bytes of that prefix of text
+func foldPrefix(text, query string) (int, bool) {
+	n := 0
+	for _, q := range query {
+		if n >= len(text) {
+			return 0, false
+		}
+		r, size := utf8.DecodeRuneInString(text[n:])
+		if r != q && !strings.EqualFold(string(r), string(q)) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
+
+// Text returns the output without escape sequences
+func (v OutputViewer) Text() string {
+	if len(v.plain) == 0 {
+		return ""
+	}
+	return strings.Join(v.plain, "\n") + "\n"
+}
+
+// Save writes the output without colours to dir, named after the script
+// and the current time, and reports where it went
+func (v *OutputViewer) Save(dir, scriptName string) {
+	name := strings.TrimSuffix(scriptName, filepath.Ext(scriptName))
+	if name == "" {
+		name = "salida"
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%s-%s.log", name, time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(file, []byte(v.Text()), 0644); err != nil {
+		v.notice = "✗ No se pudo guardar: " + err.Error()
+		return
+	}
+	v.notice = "Salida guardada en " + file
+}
+
+// copyToClipboard copies the output through the terminal with OSC 52, which
+// also works over SSH; the terminal must allow it. The sequence is written
+// with tea.Exec, while the renderer is paused, so it does not interleave
+// with a frame.
+func (v *OutputViewer) copyToClipboard() tea.Cmd {
+	text := v.Text()
+	if text == "" {
+		v.notice = "No hay salida que copiar"
+		return nil
+	}
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	v.notice = fmt.Sprintf("Copiadas %d líneas al portapapeles (OSC 52)", len(v.plain))
+	return tea.Exec(&clipboardWrite{seq: seq}, nil)
+}
+
+// clipboardWrite is the tea.ExecCommand that writes an OSC 52 sequence to
+// the program's output
+type clipboardWrite struct {
+	seq osc52.Sequence
+	out io.Writer
+}
+
+func (c *clipboardWrite) Run() error {
+	_, err := c.seq.WriteTo(c.out)
+	return err
+}
+
+func (c *clipboardWrite) SetStdin(io.Reader)    {}
+func (c *clipboardWrite) SetStdout(w io.Writer) { c.out = w }
+func (c *clipboardWrite) SetStderr(io.Writer)   {}
+
+// View renders the visible rows, the position and the search prompt
+func (v OutputViewer) View() string {
+	if len(v.lines) == 0 {
+		return ui.DimStyle.Render("(Sin salida)") + "\n"
+	}
+
+	rows := v.rows()
+	end := v.offset + v.height
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	current := -1
+	if len(v.matches) > 0 {
+		current = v.matches[v.match]
+	}
+
+	var b strings.Builder
+	for _, row := range rows[v.offset:end] {
+		text := row.text
+		if start, _ := findMatch(v.plain[row.line], v.query); start >= 0 {
+			// Lines with a match lose their colours to show the match
+			text = markMatches(ansi.Strip(text), v.query, row.line == current)
+		}
+		if !v.wrap {
+			text = ansi.Cut(text, v.column, v.column+v.width)
+		}
+		b.WriteString(text + "\x1b[0m\n")
+	}
+
+	position := fmt.Sprintf("[Líneas %d-%d de %d]", v.offset+1, end, len(rows))
+	if len(rows) <= v.height {
+		position = fmt.Sprintf("[%d líneas]", len(rows))
+	}
+	if !v.wrap {
+		position += fmt.Sprintf(" [col %d]", v.column+1)
+	}
+	b.WriteString("\n" + ui.DimStyle.Render(position))
+	if v.notice != "" {
+		b.WriteString("  " + ui.WarningStyle.Render(v.notice))
+	}
+	b.WriteString("\n")
+	if v.searching {
+		b.WriteString(v.input.View() + "\n")
+	}
+	return b.String()
+}
+
+// markMatches marks every occurrence of query in a plain text row
+func markMatches(text, query string, current bool) string {
+	style := ui.MatchStyle
+	if current {
+		style = ui.CurrentMatchStyle
+	}
+	var b strings.Builder
+	for {
+		start, end := findMatch(text, query)
+		if start < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:start])
+		b.WriteString(style.Render(text[start:end]))
+		text = text[end:]
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestFindMatch(t *testing.T) {
+	tests := []struct {
+		text, query string
+		want        string // matched text, "" = no match
+	}{
+		{"Error: build failed", "error", "Error"},
+		{"Error: build failed", "Error", "Error"},
+		{"error: build failed", "Error", ""},
+		{"İx", "x", "x"},
+		{"İstanbul", "stan", "stan"},
+		{"ÉCOLE école", "école", "ÉCOLE"},
+		{"straße", "SS", ""},
+		{"abc", "", ""},
+		{"abc", "abcd", ""},
+	}
+	for _, tt := range tests {
+		start, end := findMatch(tt.text, tt.query)
+		got := ""
+		if start >= 0 {
+			got = tt.text[start:end]
+		}
+		if got != tt.want {
+			t.Errorf("findMatch(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMarkMatchesKeepsText(t *testing.T) {
+	for _, text := range []string{"İx İx", "x", "ÉCOLE école", "sin coincidencias"} {
+		marked := markMatches(text, "x", false)
+		if got := ansi.Strip(marked); got != text {
+			t.Errorf("markMatches(%q) changed the text to %q", text, got)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Parameter types accepted by @param
+const (
+	ParamString = "string"
+	ParamInt    = "int"
+	ParamBool   = "bool"
+	ParamChoice = "choice"
+	ParamPath   = "path"
+)
+
+// ScriptParam is an input declared in a script header with @param:
+//
+//	# @param MODULE_NAME "Nombre del módulo" default=module required env
+//	# @param target type=choice choices=linux,windows,mac default=linux
+//
+// Parameters with env are passed as environment variables (env alone uses the
+// parameter name, env=VAR sets another one); the rest are passed as positional
+// arguments in declaration order, after the header @args.
+type ScriptParam struct {
+	Name     string
+	Label    string
+	Type     string
+	Default  string
+	Choices  []string
+	Required bool
+	Env      string
+}
+
+// RunOptions carries per-run inputs for a script execution
+type RunOptions struct {
+	Args []string // extra positional arguments, appended after the header @args
+	Env  []string // extra KEY=VALUE environment entries
+}
+
+// parseParamDirective parses the value of a @param header line
+func parseParamDirective(value string) (ScriptParam, bool) {
+	tokens := splitArgs(value)
+	if len(tokens) == 0 {
+		return ScriptParam{}, false
+	}
+
+	param := ScriptParam{Name: tokens[0], Type: ParamString}
+	var label []string
+	for _, token := range tokens[1:] {
+		key, val, hasValue := strings.Cut(token, "=")
+		switch strings.ToLower(key) {
+		case "type":
+			param.Type = strings.ToLower(val)
+		case "default":
+			param.Default = val
+		case "choices":
+			param.Choices = strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == '|' })
+		case "required":
+			param.Required = !hasValue || parseHeaderBool(val)
+		case "env":
+			param.Env = param.Name
+			if hasValue && val != "" {
+				param.Env = val
+			}
+		case "label", "desc":
+			label = append(label, val)
+		default:
+			label = append(label, token)
+		}
+	}
+
+	if len(param.Choices) > 0 && param.Type == ParamString {
+		param.Type = ParamChoice
+	}
+	switch param.Type {
+	case ParamString, ParamInt, ParamBool, ParamChoice, ParamPath:
+	default:
+		param.Type = ParamString
+	}
+
+	param.Label = strings.Join(label, " ")
+	if param.Label == "" {
+		param.Label = param.Name
+	}
+	return param, true
+}
+
+// Normalize validates a value for the parameter and returns it in the form
+// passed to the script. Empty values fall back to the default.
+func (p ScriptParam) Normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = p.Default
+	}
+	if value == "" {
+		if p.Required {
+			return "", fmt.Errorf("%s es obligatorio", p.Label)
+		}
+		return "", nil
+	}
+
+	switch p.Type {
+	case ParamInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("%s debe ser un número entero", p.Label)
+		}
+	case ParamBool:
+		switch strings.ToLower(value) {
+		case "true", "yes", "si", "sí", "s", "y", "1", "on":
+			value = "true"
+		case "false", "no", "n", "0", "off":
+			value = "false"
+		default:
+			return "", fmt.Errorf("%s debe ser sí/no", p.Label)
+		}
+	case ParamChoice:
+		valid := false
+		for _, choice := range p.Choices {
+			if choice == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", fmt.Errorf("%s debe ser uno de: %s", p.Label, strings.Join(p.Choices, ", "))
+		}
+	case ParamPath:
+		if strings.HasPrefix(value, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				value = filepath.Join(home, strings.TrimPrefix(value, "~"))
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// BuildRunOptions validates the values entered for each parameter and maps
+// them to positional arguments or environment variables. errs has one entry
+// per parameter (nil when valid).
+func BuildRunOptions(params []ScriptParam, values []string) (RunOptions, []error, bool) {
+	var opts RunOptions
+	errs := make([]error, len(params))
+	ok := true
+
+	for i, param := range params {
+		raw := ""
+		if i < len(values) {
+			raw = values[i]
+		}
+		value, err := param.Normalize(raw)
+		if err != nil {
+			errs[i] = err
+			ok = false
+			continue
+		}
+		if param.Env != "" {
+			opts.Env = append(opts.Env, param.Env+"="+value)
+		} else {
+			opts.Args = append(opts.Args, value)
+		}
+	}
+
+	return opts, errs, ok
+}
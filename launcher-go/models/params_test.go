@@ -0,0 +1,104 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseParamDirective(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ScriptParam
+	}{
+		{
+			`MODULE_NAME "Nombre del módulo" default=module required env`,
+			ScriptParam{Name: "MODULE_NAME", Label: "Nombre del módulo", Type: ParamString, Default: "module", Required: true, Env: "MODULE_NAME"},
+		},
+		{
+			`target type=choice choices=linux,windows|mac default=linux`,
+			ScriptParam{Name: "target", Label: "target", Type: ParamChoice, Default: "linux", Choices: []string{"linux", "windows", "mac"}},
+		},
+		{
+			`target choices=a,b`,
+			ScriptParam{Name: "target", Label: "target", Type: ParamChoice, Choices: []string{"a", "b"}},
+		},
+		{
+			`count type=INT env=N label="Número de copias" required=no`,
+			ScriptParam{Name: "count", Label: "Número de copias", Type: ParamInt, Env: "N"},
+		},
+		{
+			`x type=fecha`,
+			ScriptParam{Name: "x", Label: "x", Type: ParamString},
+		},
+	}
+	for _, tt := range tests {
+		got, ok := parseParamDirective(tt.in)
+		if !ok {
+			t.Errorf("parseParamDirective(%q) failed", tt.in)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseParamDirective(%q) =\n%+v\nwant\n%+v", tt.in, got, tt.want)
+		}
+	}
+	if _, ok := parseParamDirective("  "); ok {
+		t.Error("parseParamDirective accepted an empty @param")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		param   ScriptParam
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{ScriptParam{Label: "n", Type: ParamString, Default: "module"}, "  ", "module", false},
+		{ScriptParam{Label: "n", Type: ParamString, Required: true}, "", "", true},
+		{ScriptParam{Label: "n", Type: ParamString}, "", "", false},
+		{ScriptParam{Label: "n", Type: ParamInt}, "12", "12", false},
+		{ScriptParam{Label: "n", Type: ParamInt}, "doce", "", true},
+		{ScriptParam{Label: "n", Type: ParamBool}, "Sí", "true", false},
+		{ScriptParam{Label: "n", Type: ParamBool}, "off", "false", false},
+		{ScriptParam{Label: "n", Type: ParamBool}, "quizá", "", true},
+		{ScriptParam{Label: "n", Type: ParamChoice, Choices: []string{"linux", "mac"}}, "mac", "mac", false},
+		{ScriptParam{Label: "n", Type: ParamChoice, Choices: []string{"linux", "mac"}}, "Mac", "", true},
+		{ScriptParam{Label: "n", Type: ParamPath}, "~/src", filepath.Join(home, "src"), false},
+	}
+	for _, tt := range tests {
+		got, err := tt.param.Normalize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s.Normalize(%q) = %q, %v; want %q (error %v)", tt.param.Type, tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildRunOptions(t *testing.T) {
+	params := []ScriptParam{
+		{Name: "name", Label: "name", Type: ParamString},
+		{Name: "TOKEN", Label: "TOKEN", Type: ParamString, Env: "TOKEN"},
+		{Name: "count", Label: "count", Type: ParamInt, Default: "1"},
+	}
+
+	opts, errs, ok := BuildRunOptions(params, []string{"demo", "abc"})
+	if !ok {
+		t.Fatalf("BuildRunOptions failed: %v", errs)
+	}
+	if want := []string{"demo", "1"}; !reflect.DeepEqual(opts.Args, want) {
+		t.Errorf("Args = %q, want %q", opts.Args, want)
+	}
+	if want := []string{"TOKEN=abc"}; !reflect.DeepEqual(opts.Env, want) {
+		t.Errorf("Env = %q, want %q", opts.Env, want)
+	}
+
+	_, errs, ok = BuildRunOptions(params, []string{"demo", "", "x"})
+	if ok || errs[0] != nil || errs[1] != nil || errs[2] == nil {
+		t.Errorf("BuildRunOptions with a bad int = %v, %v", errs, ok)
+	}
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/lucas/launcher/semver"
+	"github.com/lucas/launcher/ui"
+)
+
+// requirementCheck is one line of the pre-flight report
+type requirementCheck struct {
+	Label  string
+	OK     bool
+	Detail string
+}
+
+// hasRequirements reports whether the script declares anything to check
+func (s Script) hasRequirements() bool {
+	return len(s.Requires) > 0 || len(s.RequiresEnv) > 0 || len(s.RequiresOS) > 0 || s.MinLauncher != "" || s.Root || s.Sudo
+}
+
+// checkRequirements evaluates the @requires, @requires-env, @requires-os,
+// @requires-launcher, @root and @sudo headers of a script on this machine
+func checkRequirements(script Script) []requirementCheck {
+	var checks []requirementCheck
+
+	for _, name := range script.Requires {
+		check := requirementCheck{Label: "comando " + name}
+		if path, err := exec.LookPath(name); err == nil {
+			check.OK, check.Detail = true, path
+		} else {
+			check.Detail = "no está en el PATH"
+		}
+		checks = append(checks, check)
+	}
+
+	for _, name := range script.RequiresEnv {
+		check := requirementCheck{Label: "variable " + name}
+		if os.Getenv(name) != "" {
+			check.OK, check.Detail = true, "definida"
+		} else {
+			check.Detail = "no definida o vacía"
+		}
+		checks = append(checks, check)
+	}
+
+	if len(script.RequiresOS) > 0 {
+		info := currentOS()
+		check := requirementCheck{Label: "sistema " + strings.Join(script.RequiresOS, " o "), Detail: info.String()}
+		for _, spec := range script.RequiresOS {
+			if info.Satisfies(spec) {
+				check.OK = true
+				break
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	if script.MinLauncher != "" {
+		checks = append(checks, checkLauncherVersion(script.MinLauncher))
+	}
+
+	switch {
+	case script.Root:
+		check := requirementCheck{Label: "permisos de administrador", OK: isPrivileged()}
+		if check.OK {
+			check.Detail = "sí"
+		} else {
+			check.Detail = "ejecuta el launcher como root/administrador"
+		}
+		checks = append(checks, check)
+	case script.Sudo:
+		// Only whether sudo exists: a password or sudoers rule can still refuse
+		check := requirementCheck{Label: "sudo instalado"}
+		if isPrivileged() {
+			check.OK, check.Detail = true, "ya eres root"
+		} else if path, err := exec.LookPath("sudo"); err == nil {
+			check.OK, check.Detail = true, path
+		} else {
+			check.Detail = "sudo no está instalado"
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// unmetRequirements returns the labels of the failed checks
+func unmetRequirements(checks []requirementCheck) []string {
+	var unmet []string
+	for _, check := range checks {
+		if !check.OK {
+			unmet = append(unmet, check.Label)
+		}
+	}
+	return unmet
+}
+
+// refreshRequirements re-evaluates the requirements of the listed scripts,
+// e.g. after an installer ran
+func (m *Model) refreshRequirements() {
+	for i := range m.scripts {
+		if m.scripts[i].hasRequirements() {
+			m.scripts[i].Unmet = unmetRequirements(checkRequirements(m.scripts[i]))
+		}
+	}
+}
+
+// renderPreflight renders the pre-flight report shown before a script with
+// unmet requirements runs
+func renderPreflight(script Script, checks []requirementCheck) string {
+	content := "\n"
+	content += ui.WarningStyle.Render("⚠ Requisitos no cumplidos: "+script.DisplayName()) + "\n\n"
+	for _, check := range checks {
+		mark := ui.SuccessStyle.Render("✓")
+		if !check.OK {
+			mark = ui.ErrorStyle.Render("✗")
+		}
+		content += fmt.Sprintf("  %s %-32s %s\n", mark, check.Label, ui.DimStyle.Render(check.Detail))
+	}
+	content += "\n" + ui.DimStyle.Render("El script probablemente fallará.") + "\n"
+	content += "\n" + ui.DimStyle.Render("c: continuar de todos modos  /: buscar  esc/n: cancelar")
+	return content
+}
+
+// osInfo identifies the running system for @requires-os
+type osInfo struct {
+	ID      string   // linux distribution id, "macos" or "windows"
+	Like    []string // ID_LIKE of the distribution
+	Version string
+}
+
+var (
+	osInfoOnce sync.Once
+	osInfoData osInfo
+)
+
+func currentOS() osInfo {
+	osInfoOnce.Do(func() { osInfoData = readOSInfo() })
+	return osInfoData
+}
+
+func (o osInfo) String() string {
+	s := o.ID
+	if o.Version != "" {
+		s += " " + o.Version
+	}
+	return s
+}
+
+// Satisfies checks one @requires-os entry: "linux", "ubuntu", "macos>=13"
+// or "windows>=10.0.22000". The name matches the OS, the distribution or a
+// distribution it derives from.
+func (o osInfo) Satisfies(spec string) bool {
+	name, minVersion, _ := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ">=")
+	name = strings.TrimSpace(name)
+	if name == "darwin" {
+		name = "macos"
+	}
+
+	matches := name == runtime.GOOS || name == o.ID
+	for _, like := range o.Like {
+		matches = matches || name == like
+	}
+	if !matches {
+		return false
+	}
+	if minVersion = strings.TrimSpace(minVersion); minVersion == "" {
+		return true
+	}
+	// Versions are those of the distribution (VERSION_ID), macOS or Windows,
+	// so "linux>=X" or "debian>=X" on Ubuntu never match
+	if name != o.ID {
+		return false
+	}
+	have, err := semver.Coerce(o.Version)
+	if err != nil {
+		return false
+	}
+	want, err := semver.Coerce(minVersion)
+	return err == nil && have.Compare(want) >= 0
+}
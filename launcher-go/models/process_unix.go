@@ -0,0 +1,105 @@
+//go:build unix
+
+package models
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+	"golang.org/x/sys/unix"
+)
+
+// configureProcessGroup starts the script in its own process group so the
+// whole tree (script and the commands it spawns) can be signalled at once.
+// When the script gets the terminal its group is made the foreground one, so
+// Ctrl+C and keyboard input reach it directly.
+func configureProcessGroup(cmd *exec.Cmd) (foreground bool) {
+	attr := &syscall.SysProcAttr{Setpgid: true}
+	if f, ok := cmd.Stdin.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		attr.Foreground = true
+		attr.Ctty = int(f.Fd())
+		foreground = true
+	}
+	cmd.SysProcAttr = attr
+	return foreground
+}
+
+// reclaimTerminal makes the launcher's process group the foreground one again
+// after a script that had the terminal finished
+func reclaimTerminal(cmd *exec.Cmd) {
+	f, ok := cmd.Stdin.(*os.File)
+	if !ok {
+		return
+	}
+	// tcsetpgrp from a background group raises SIGTTOU
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(int(f.Fd()), unix.TIOCSPGRP, unix.Getpgrp())
+}
+
+// interruptProcessGroup sends SIGINT to every process of the script's group
+func interruptProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+	}
+}
+
+// killProcessGroup sends SIGKILL to every process of the script's group
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// exitCodeOf returns the exit code of a finished process, using the shell
+// convention 128+N for processes killed by signal N. interrupted reports
+// whether the process died from SIGINT.
+func exitCodeOf(state *os.ProcessState) (code int, interrupted bool) {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), ws.Signal() == syscall.SIGINT
+	}
+	return state.ExitCode(), state.ExitCode() == 130
+}
+
+// isPrivileged reports whether the launcher runs as root
+func isPrivileged() bool {
+	return os.Geteuid() == 0
+}
+
+// readOSInfo reads /etc/os-release on Linux and sw_vers on macOS
+func readOSInfo() osInfo {
+	if runtime.GOOS == "darwin" {
+		info := osInfo{ID: "macos"}
+		if out, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+			info.Version = strings.TrimSpace(string(out))
+		}
+		return info
+	}
+
+	info := osInfo{ID: runtime.GOOS}
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return info
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			info.ID = strings.ToLower(value)
+		case "ID_LIKE":
+			info.Like = strings.Fields(strings.ToLower(value))
+		case "VERSION_ID":
+			info.Version = value
+		}
+	}
+	return info
+}
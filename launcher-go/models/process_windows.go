@@ -0,0 +1,53 @@
+//go:build windows
+
+package models
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// configureProcessGroup keeps the script in the console's process group so
+// Ctrl+C still reaches it; the tree is stopped with taskkill /T instead.
+func configureProcessGroup(cmd *exec.Cmd) (foreground bool) {
+	return false
+}
+
+// reclaimTerminal is a no-op on Windows: consoles have no foreground group
+func reclaimTerminal(cmd *exec.Cmd) {}
+
+// interruptProcessGroup asks the script and its children to close
+func interruptProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = exec.Command("taskkill", "/PID", strconv.Itoa(cmd.Process.Pid), "/T").Run()
+	}
+}
+
+// killProcessGroup forcibly terminates the script and its children
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = exec.Command("taskkill", "/PID", strconv.Itoa(cmd.Process.Pid), "/T", "/F").Run()
+	}
+}
+
+// exitCodeOf returns the exit code of a finished process. interrupted reports
+// a Ctrl+C termination (STATUS_CONTROL_C_EXIT).
+func exitCodeOf(state *os.ProcessState) (code int, interrupted bool) {
+	code = state.ExitCode()
+	return code, uint32(code) == 0xC000013A
+}
+
+// isPrivileged reports whether the launcher runs elevated (administrator)
+func isPrivileged() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// readOSInfo returns the Windows version as major.minor.build
+func readOSInfo() osInfo {
+	v := windows.RtlGetVersion()
+	return osInfo{ID: "windows", Version: fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber)}
+}
@@ -0,0 +1,313 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/utils"
+)
+
+// Origins of a script root
+const (
+	OriginInstall = "install"
+	OriginUser    = "user"
+	OriginTeam    = "team"
+	OriginProject = "project"
+)
+
+// TeamDirsEnv lists extra script roots (team repos), separated by the OS path list separator
+const TeamDirsEnv = "DEVLAUNCHER_SCRIPT_DIRS"
+
+// ScriptRoot is one scripts tree merged into the launcher. Path is the
+// platform folder of the tree (the one holding the categories).
+type ScriptRoot struct {
+	Origin string `json:"origin" yaml:"origin"`
+	Path   string `json:"path" yaml:"path"`
+}
+
+// ResolveScriptRoots returns the script roots in precedence order, lowest
+// first: install dir, user config dir, team dirs (script_dirs in the config
+// file, then DEVLAUNCHER_SCRIPT_DIRS) and the nearest project
+// .devlauncher/scripts found walking up from startDir, below $HOME. Only the
+// install root is kept when it does not exist; later roots override earlier
+// ones.
+func ResolveScriptRoots(rootDir, startDir string) []ScriptRoot {
+	roots := []ScriptRoot{{Origin: OriginInstall, Path: utils.GetScriptsPath(rootDir)}}
+
+	add := func(origin, path string) {
+		if path == "" {
+			return
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+		for _, root := range roots {
+			if utils.SamePath(root.Path, abs) {
+				return
+			}
+		}
+		roots = append(roots, ScriptRoot{Origin: origin, Path: abs})
+	}
+
+	add(OriginUser, platformScriptsDir(filepath.Join(utils.GetUserConfigDir(), "scripts")))
+	teamDirs := append([]string(nil), config.Current().ScriptDirs...)
+	teamDirs = append(teamDirs, filepath.SplitList(os.Getenv(TeamDirsEnv))...)
+	for _, dir := range teamDirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		add(OriginTeam+":"+filepath.Base(dir), platformScriptsDir(expandHome(dir)))
+	}
+	if project := utils.FindProjectScriptsDir(startDir, rootDir); project != "" {
+		add(OriginProject, platformScriptsDir(project))
+	}
+	return roots
+}
+
+// platformScriptsDir picks the platform folder of a scripts tree when it has
+// one (dir/scripts/<platform> or dir/<platform>), or dir itself
+func platformScriptsDir(dir string) string {
+	platform := string(utils.DetectPlatform())
+	for _, candidate := range []string{
+		filepath.Join(dir, "scripts", platform),
+		filepath.Join(dir, platform),
+	} {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+	}
+	return dir
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// OriginLabel returns the short label shown in the UI for an origin
+func OriginLabel(origin string) string {
+	switch {
+	case origin == OriginInstall:
+		return "base"
+	case origin == OriginUser:
+		return "usuario"
+	case origin == OriginProject:
+		return "proyecto"
+	case strings.HasPrefix(origin, OriginTeam+":"):
+		return "equipo:" + strings.TrimPrefix(origin, OriginTeam+":")
+	}
+	return origin
+}
+
+// sourceOrigins lists the distinct origins of a merged folder, highest precedence first
+func sourceOrigins(sources []ScriptRoot) []string {
+	var origins []string
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if !seen[source.Origin] {
+			seen[source.Origin] = true
+			origins = append(origins, source.Origin)
+		}
+	}
+	return origins
+}
+
+// originBadge returns the origin tag shown next to an item, empty for items
+// that only come from the install dir
+func originBadge(origins ...string) string {
+	if len(origins) == 0 || (len(origins) == 1 && origins[0] == OriginInstall) {
+		return ""
+	}
+	labels := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		labels = append(labels, OriginLabel(origin))
+	}
+	return "[" + strings.Join(labels, "+") + "]"
+}
+
+// ScanMergedScripts lists a folder that may exist in several roots. Sources
+// go highest precedence first. Folders with the same name are merged; a
+// script hides same-named scripts (extension ignored) of lower roots.
+func ScanMergedScripts(sources []ScriptRoot) ([]Script, error) {
+	var merged []Script
+	index := map[string]int{}
+	found := false
+	var firstErr error
+
+	for _, source := range sources {
+		items, err := ScanScripts(source.Path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		found = true
+
+		for _, item := range items {
+			item.Origin = source.Origin
+			key := "s:" + strings.TrimSuffix(item.Name, item.Extension)
+			if item.Extension == ".dir" {
+				key = "d:" + item.Name
+				item.Sources = []ScriptRoot{{Origin: source.Origin, Path: item.Path}}
+			}
+
+			i, exists := index[key]
+			if !exists {
+				index[key] = len(merged)
+				merged = append(merged, item)
+				continue
+			}
+			if item.Extension == ".dir" {
+				merged[i].Sources = append(merged[i].Sources, item.Sources...)
+			} else {
+				merged[i].Overrides = append(merged[i].Overrides, source.Origin)
+			}
+		}
+	}
+	if !found {
+		return nil, firstErr
+	}
+
+	for i := range merged {
+		if len(merged[i].Sources) > 1 {
+			merged[i].DirCount, merged[i].ScriptCount = countMergedItems(merged[i].Sources)
+			merged[i].Icon, merged[i].Description = mergedFolderMeta(merged[i].Sources, merged[i].Name)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		iDir := merged[i].Extension == ".dir"
+		jDir := merged[j].Extension == ".dir"
+		if iDir != jDir {
+			return iDir
+		}
+		return merged[i].Name < merged[j].Name
+	})
+	return merged, nil
+}
+
+// countMergedItems counts the distinct subfolders and scripts of a merged folder
+func countMergedItems(sources []ScriptRoot) (int, int) {
+	dirs := map[string]bool{}
+	scripts := map[string]bool{}
+	for _, source := range sources {
+		items, err := ScanScripts(source.Path)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			if item.Extension == ".dir" {
+				dirs[item.Name] = true
+			} else {
+				scripts[strings.TrimSuffix(item.Name, item.Extension)] = true
+			}
+		}
+	}
+	return len(dirs), len(scripts)
+}
+
+// folderRel returns the slash-separated path of dir inside the category,
+// whichever root it belongs to
+func folderRel(cat Category, dir string) (string, bool) {
+	for _, source := range cat.Sources {
+		rel, err := filepath.Rel(source.Path, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// folderSources returns the folders at rel inside every root of the category
+func folderSources(cat Category, rel string) []ScriptRoot {
+	var sources []ScriptRoot
+	for _, source := range cat.Sources {
+		dir := filepath.Join(source.Path, filepath.FromSlash(rel))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			sources = append(sources, ScriptRoot{Origin: source.Origin, Path: dir})
+		}
+	}
+	return sources
+}
+
+// WalkTree visits every folder and script of the merged categories. rel is
+// the slash-separated path of the item inside its category.
+func WalkTree(categories []Category, fn func(cat Category, rel string, item Script)) {
+	var walk func(cat Category, prefix string, sources []ScriptRoot)
+	walk = func(cat Category, prefix string, sources []ScriptRoot) {
+		items, err := ScanMergedScripts(sources)
+		if err != nil {
+			return
+		}
+		for _, item := range items {
+			rel := item.Name
+			if prefix != "" {
+				rel = prefix + "/" + item.Name
+			}
+			if item.Extension != ".dir" {
+				item.Category = cat.Name
+				item.EnvFiles = envFilesFor(cat, prefix)
+			}
+			fn(cat, rel, item)
+			if item.Extension == ".dir" {
+				walk(cat, rel, item.Sources)
+			}
+		}
+	}
+
+	for _, cat := range categories {
+		if cat.Favorites {
+			continue
+		}
+		walk(cat, "", cat.Sources)
+	}
+}
+
+// loadFolder lists a merged folder of cat, rel being its path inside the category
+func loadFolder(cat Category, rel string, sources []ScriptRoot) tea.Cmd {
+	return func() tea.Msg {
+		scripts, err := ScanMergedScripts(sources)
+		if err != nil {
+			return errorMsg{err}
+		}
+		envFiles := envFilesFor(cat, rel)
+		for i := range scripts {
+			if scripts[i].Extension != ".dir" {
+				scripts[i].Category = cat.Name
+				scripts[i].EnvFiles = envFiles
+			}
+		}
+		return scriptsLoadedMsg{scripts: scripts}
+	}
+}
+
+// loadCurrentFolder reloads ScriptView for currentPath, merging the same
+// folder from every root of the current category
+func (m *Model) loadCurrentFolder() tea.Cmd {
+	if m.currentCategory.Favorites {
+		if m.currentPath == "" {
+			return loadFavoriteScripts(m.favorites)
+		}
+		return loadScripts(m.currentPath)
+	}
+	rel, ok := folderRel(m.currentCategory, m.currentPath)
+	if !ok {
+		return loadScripts(m.currentPath)
+	}
+	return loadFolder(m.currentCategory, rel, folderSources(m.currentCategory, rel))
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// testScriptExt is a script extension listed on this platform
+func testScriptExt() string {
+	if runtime.GOOS == "windows" {
+		return ".ps1"
+	}
+	return ".sh"
+}
+
+// makeTree creates the given files (slash-separated, relative to dir)
+func makeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, rel := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("echo "+rel+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanMergedScripts(t *testing.T) {
+	ext := testScriptExt()
+	install, project := t.TempDir(), t.TempDir()
+	makeTree(t, install, "a"+ext, "b"+ext, "tools/x"+ext, "tools/y"+ext)
+	makeTree(t, project, "a"+ext, "c"+ext, "tools/x"+ext, "tools/sub/z"+ext)
+
+	sources := []ScriptRoot{
+		{Origin: OriginProject, Path: project},
+		{Origin: OriginUser, Path: filepath.Join(t.TempDir(), "missing")},
+		{Origin: OriginInstall, Path: install},
+	}
+	items, err := ScanMergedScripts(sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	if want := []string{"tools", "a" + ext, "b" + ext, "c" + ext}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+
+	tools := items[0]
+	wantSources := []ScriptRoot{
+		{Origin: OriginProject, Path: filepath.Join(project, "tools")},
+		{Origin: OriginInstall, Path: filepath.Join(install, "tools")},
+	}
+	if !reflect.DeepEqual(tools.Sources, wantSources) {
+		t.Errorf("tools.Sources = %+v, want %+v", tools.Sources, wantSources)
+	}
+	if tools.DirCount != 1 || tools.ScriptCount != 2 {
+		t.Errorf("tools counts = %d dirs, %d scripts; want 1, 2", tools.DirCount, tools.ScriptCount)
+	}
+
+	a := items[1]
+	if a.Origin != OriginProject || a.Path != filepath.Join(project, "a"+ext) {
+		t.Errorf("a comes from %s (%s), want the project root", a.Origin, a.Path)
+	}
+	if want := []string{OriginInstall}; !reflect.DeepEqual(a.Overrides, want) {
+		t.Errorf("a.Overrides = %q, want %q", a.Overrides, want)
+	}
+	if b := items[2]; b.Origin != OriginInstall || len(b.Overrides) != 0 {
+		t.Errorf("b = %s, overrides %q", b.Origin, b.Overrides)
+	}
+}
+
+func TestScanMergedScriptsMissingRoots(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := ScanMergedScripts([]ScriptRoot{{Origin: OriginInstall, Path: missing}}); err == nil {
+		t.Error("ScanMergedScripts succeeded without any root")
+	}
+}
+
+func TestOriginBadge(t *testing.T) {
+	tests := []struct {
+		origins []string
+		want    string
+	}{
+		{nil, ""},
+		{[]string{OriginInstall}, ""},
+		{[]string{OriginUser}, "[usuario]"},
+		{[]string{OriginProject, "team:infra", OriginInstall}, "[proyecto+equipo:infra+base]"},
+	}
+	for _, tt := range tests {
+		if got := originBadge(tt.origins...); got != tt.want {
+			t.Errorf("originBadge(%q) = %q, want %q", tt.origins, got, tt.want)
+		}
+	}
+}
+
+func TestFolderRel(t *testing.T) {
+	install, user := t.TempDir(), t.TempDir()
+	cat := Category{Name: "dev", Sources: []ScriptRoot{
+		{Origin: OriginUser, Path: user},
+		{Origin: OriginInstall, Path: install},
+	}}
+	tests := []struct {
+		dir    string
+		want   string
+		wantOK bool
+	}{
+		{install, ".", true},
+		{filepath.Join(user, "go", "tools"), "go/tools", true},
+		{filepath.Join(install, "go"), "go", true},
+		{filepath.Dir(install), "", false},
+	}
+	for _, tt := range tests {
+		got, ok := folderRel(cat, tt.dir)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("folderRel(%q) = %q, %v; want %q, %v", tt.dir, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lucas/launcher/ui"
+	"github.com/lucas/launcher/utils"
+	"github.com/mattn/go-isatty"
+)
+
+// FindRootDir resolves the DevLauncher root directory (the one containing scripts/)
+func FindRootDir() string {
+	// Strategy 1: Check if scripts/ exists in parent of current dir
+	if cwd, err := os.Getwd(); err == nil {
+		if _, err := os.Stat(filepath.Join(cwd, "..", "scripts")); err == nil {
+			rootDir, _ := filepath.Abs("..")
+			return rootDir
+		} else if _, err := os.Stat(filepath.Join(cwd, "scripts")); err == nil {
+			return cwd
+		}
+	}
+
+	// Strategy 2: Use executable path
+	execPath, _ := os.Executable()
+	realPath, _ := filepath.EvalSymlinks(execPath)
+	return filepath.Dir(realPath)
+}
+
+// FindScript resolves a logical script path such as "instaladores/instalar_go"
+// in the merged tree of every root. The extension is optional. A bare name
+// without folders is searched in the whole tree and must be unique; when
+// several roots have the same script the highest-precedence one is used.
+func FindScript(roots []ScriptRoot, logical string) (Script, error) {
+	logical = strings.Trim(filepath.ToSlash(strings.TrimSpace(logical)), "/")
+	if logical == "" {
+		return Script{}, fmt.Errorf("ruta de script vacía")
+	}
+
+	categories, err := ScanCategories(roots)
+	if err != nil {
+		return Script{}, err
+	}
+
+	if !strings.Contains(logical, "/") {
+		var matches []Script
+		var paths []string
+		WalkTree(categories, func(cat Category, rel string, item Script) {
+			if scriptMatchesName(item, logical) {
+				matches = append(matches, item)
+				paths = append(paths, "  "+cat.Name+"/"+strings.TrimSuffix(rel, item.Extension))
+			}
+		})
+		switch len(matches) {
+		case 0:
+			return Script{}, fmt.Errorf("script no encontrado: %s", logical)
+		case 1:
+			return matches[0], nil
+		}
+		sort.Strings(paths)
+		return Script{}, fmt.Errorf("nombre ambiguo: %s\n%s", logical, strings.Join(paths, "\n"))
+	}
+
+	folder := path.Dir(logical)
+	name := path.Base(logical)
+	categoryName, rel, _ := strings.Cut(folder, "/")
+	if rel == "" {
+		rel = "."
+	}
+	for _, cat := range categories {
+		if cat.Name != categoryName {
+			continue
+		}
+		scripts, err := ScanMergedScripts(folderSources(cat, rel))
+		if err != nil {
+			break
+		}
+		for _, script := range scripts {
+			if scriptMatchesName(script, name) {
+				script.Category = cat.Name
+				script.EnvFiles = envFilesFor(cat, rel)
+				return script, nil
+			}
+		}
+		return Script{}, fmt.Errorf("script no encontrado: %s", logical)
+	}
+	return Script{}, fmt.Errorf("carpeta no encontrada: %s", folder)
+}
+
+func scriptMatchesName(script Script, name string) bool {
+	if script.Extension == ".dir" {
+		return false
+	}
+	return script.Name == name || strings.TrimSuffix(script.Name, script.Extension) == name
+}
+
+// RunScriptCLI implements "launcher run [--skip-checks] [--yes] <ruta> [-- args...]".
+// It runs the script (or every step of a workflow) attached to the terminal
+// and returns the exit code to propagate, 3 when its declared requirements
+// are not met or 4 when the confirmation of a @confirm or dangerous script
+// is refused.
+func RunScriptCLI(args []string) int {
+	var target string
+	var scriptArgs []string
+	skipChecks := false
+	assumeYes := false
+	for i, arg := range args {
+		if arg == "--" {
+			scriptArgs = args[i+1:]
+			break
+		}
+		if arg == "--skip-checks" {
+			skipChecks = true
+			continue
+		}
+		if arg == "--yes" || arg == "-y" {
+			assumeYes = true
+			continue
+		}
+		if target != "" {
+			fmt.Fprintf(os.Stderr, "Argumento inesperado: %s (usa -- para pasar argumentos al script)\n", arg)
+			return 2
+		}
+		target = arg
+	}
+	if target == "" {
+		fmt.Fprintln(os.Stderr, "Uso: launcher run [--skip-checks] [--yes] <categoria/ruta/script> [-- args...]")
+		return 2
+	}
+
+	rootDir := FindRootDir()
+	runDir, err := os.Getwd()
+	if err != nil {
+		runDir = rootDir
+	}
+
+	roots := ResolveScriptRoots(rootDir, runDir)
+	script, err := FindScript(roots, target)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	categories, err := ScanCategories(roots)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	// dangerous_scripts patterns match the resolved path, not what was typed
+	logical := scriptLogicalPath(categories, script)
+
+	if script.IsWorkflow() {
+		if len(scriptArgs) > 0 {
+			fmt.Fprintln(os.Stderr, "Los workflows no aceptan argumentos; decláralos en args: de cada paso")
+			return 2
+		}
+		return runCLIWorkflow(script, logical, runDir, categories, skipChecks, assumeYes, utils.GetHistoryPath())
+	}
+
+	if !skipChecks && !preflightCLI(script) {
+		return 3
+	}
+
+	opts, err := cliRunOptions(script.Params, scriptArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if !assumeYes && !confirmCLI(script, logical, runDir, opts) {
+		return 4
+	}
+
+	return runCLIScript(script, runDir, opts, utils.GetHistoryPath())
+}
+
+// preflightCLI checks the declared requirements of a script. When some are
+// not met it prints the report and returns false (exit 3 for the caller).
+func preflightCLI(script Script) bool {
+	if !script.hasRequirements() {
+		return true
+	}
+	checks := checkRequirements(script)
+	if len(unmetRequirements(checks)) == 0 {
+		return true
+	}
+	fmt.Fprintln(os.Stderr, ui.WarningStyle.Render("⚠ Requisitos no cumplidos: ")+script.DisplayName())
+	for _, check := range checks {
+		mark := "✓"
+		if !check.OK {
+			mark = "✗"
+		}
+		fmt.Fprintf(os.Stderr, "  %s %-32s %s\n", mark, check.Label, check.Detail)
+	}
+	fmt.Fprintln(os.Stderr, "Usa --skip-checks para ejecutarlo de todos modos")
+	return false
+}
+
+// confirmCLI asks on the terminal before running a @confirm or dangerous
+// script. Without a terminal the run is refused: --yes is required.
+func confirmCLI(script Script, logical, runDir string, opts RunOptions) bool {
+	typed := isDangerous(script, logical)
+	if !typed && !script.Confirm {
+		return true
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "%s necesita confirmación; usa --yes para ejecutarlo sin terminal\n", script.Name)
+		return false
+	}
+
+	gate := NewConfirmGate(script, runDir, opts, typed)
+	fmt.Fprintln(os.Stderr, gate.Summary())
+	if typed {
+		fmt.Fprintf(os.Stderr, "Escribe %s para confirmar: ", confirmWord(script))
+	} else {
+		fmt.Fprint(os.Stderr, "¿Ejecutar? [s/N]: ")
+	}
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if typed {
+		return answer == confirmWord(script)
+	}
+	switch strings.ToLower(answer) {
+	case "s", "si", "sí", "y", "yes":
+		return true
+	}
+	return false
+}
+
+// runCLIScript runs a script attached to the terminal and records it in the
+// history. A timed out script returns 124, like timeout(1).
+func runCLIScript(script Script, runDir string, opts RunOptions, historyPath string) int {
+	run, err := newScriptRun(script, runDir, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	run.SetStdin(os.Stdin)
+	run.SetStdout(os.Stdout)
+	run.SetStderr(os.Stderr)
+
+	exitCode, output := run.Result(run.Run())
+	if err := AppendHistory(historyPath, run.HistoryEntry(exitCode, output, SourceCLI)); err != nil {
+		fmt.Fprintln(os.Stderr, "No se pudo guardar el historial:", err)
+	}
+
+	switch run.Status() {
+	case RunTimedOut:
+		fmt.Fprintf(os.Stderr, "Tiempo límite de %s agotado, script detenido\n", run.timeout)
+		return 124
+	case RunCancelled:
+		fmt.Fprintln(os.Stderr, "Ejecución cancelada")
+	}
+	return exitCode
+}
+
+// cliRunOptions maps command line arguments to the script's declared
+// parameters: positional parameters take the arguments in order and env
+// parameters read the current environment. Remaining arguments are appended.
+func cliRunOptions(params []ScriptParam, args []string) (RunOptions, error) {
+	values := make([]string, len(params))
+	next := 0
+	for i, param := range params {
+		if param.Env != "" {
+			values[i] = os.Getenv(param.Env)
+			continue
+		}
+		if next < len(args) {
+			values[i] = args[next]
+			next++
+		}
+	}
+
+	opts, errs, ok := BuildRunOptions(params, values)
+	if !ok {
+		for _, err := range errs {
+			if err != nil {
+				return opts, err
+			}
+		}
+	}
+	opts.Args = append(opts.Args, args[next:]...)
+	return opts, nil
+}
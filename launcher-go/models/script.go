@@ -1,12 +1,15 @@
 package models
 
 import (
-	"bufio"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/interpreter"
 )
 
 // Script represents an executable script
@@ -15,9 +18,43 @@ type Script struct {
 	Path        string
 	Description string
 	Extension   string
+	Interpreter string // Name in the interpreter registry, or the #! program
+	Direct      bool   // Executable with a #! line, run as is instead of through Interpreter
 	Icon        string
 	DirCount    int
 	ScriptCount int
+
+	// Fields declared in the script header (see ScriptMeta)
+	Title       string
+	Tags        []string
+	Requires    []string
+	RequiresEnv []string
+	RequiresOS  []string
+	MinLauncher string
+	Root        bool
+	Sudo        bool
+	Confirm     bool
+	Dangerous   bool
+	Timeout     time.Duration
+	Mode        string
+	Cwd         string
+	Args        []string
+	Params      []ScriptParam
+
+	// Requirements not satisfied on this machine (see checkRequirements)
+	Unmet []string
+
+	// Root the item comes from (see ScriptRoot). Merged folders list every
+	// root that has them in Sources; Overrides lists the lower roots whose
+	// same-named script is hidden by this one.
+	Origin    string
+	Sources   []ScriptRoot
+	Overrides []string
+
+	// Category the script is listed in and the .env files of its folder
+	// chain, outermost first (see envFilesFor)
+	Category string
+	EnvFiles []string
 }
 
 // ScanScripts scans a directory for executable scripts
@@ -28,17 +65,16 @@ func ScanScripts(categoryPath string) ([]Script, error) {
 	}
 
 	var scripts []Script
-	platform := runtime.GOOS
 
 	for _, entry := range entries {
 		entryPath := filepath.Join(categoryPath, entry.Name())
 
 		if entry.IsDir() {
-			if strings.EqualFold(entry.Name(), "lib") {
+			if isHiddenFolder(entry.Name()) {
 				continue
 			}
 
-			dirCount, scriptCount := countImmediateItems(entryPath, platform)
+			dirCount, scriptCount := countImmediateItems(entryPath)
 
 			scripts = append(scripts, Script{
 				Name:        entry.Name(),
@@ -53,27 +89,20 @@ func ScanScripts(categoryPath string) ([]Script, error) {
 		}
 
 		name := entry.Name()
-		if strings.HasPrefix(name, "example_") {
+		if isIgnoredFile(name) {
 			continue
 		}
 
-		ext := filepath.Ext(name)
-		isValid := false
-		if platform == "windows" {
-			isValid = ext == ".ps1" || ext == ".bat"
-		} else {
-			isValid = ext == ".sh"
+		if suffix := workflowSuffix(name); suffix != "" {
+			scripts = append(scripts, newWorkflowScript(entryPath, suffix))
+			continue
 		}
-		if !isValid {
+
+		if !isScriptEntry(entryPath, name) {
 			continue
 		}
 
-		scripts = append(scripts, Script{
-			Name:        name,
-			Path:        entryPath,
-			Description: extractDescription(entryPath),
-			Extension:   ext,
-		})
+		scripts = append(scripts, newScript(entryPath, filepath.Ext(name)))
 	}
 
 	// Sort folders first, then scripts, alphabetically.
@@ -89,7 +118,7 @@ func ScanScripts(categoryPath string) ([]Script, error) {
 	return scripts, nil
 }
 
-func countImmediateItems(folderPath, platform string) (int, int) {
+func countImmediateItems(folderPath string) (int, int) {
 	entries, err := os.ReadDir(folderPath)
 	if err != nil {
 		return 0, 0
@@ -99,71 +128,95 @@ func countImmediateItems(folderPath, platform string) (int, int) {
 	scriptCount := 0
 	for _, entry := range entries {
 		if entry.IsDir() {
-			if !strings.EqualFold(entry.Name(), "lib") {
+			if !isHiddenFolder(entry.Name()) {
 				dirCount++
 			}
 			continue
 		}
 
 		name := entry.Name()
-		if strings.HasPrefix(name, "example_") {
+		if isIgnoredFile(name) {
 			continue
 		}
 
-		ext := filepath.Ext(name)
-		if platform == "windows" {
-			if ext == ".ps1" || ext == ".bat" {
-				scriptCount++
-			}
-		} else {
-			if ext == ".sh" {
-				scriptCount++
-			}
+		if workflowSuffix(name) != "" || isScriptEntry(filepath.Join(folderPath, name), name) {
+			scriptCount++
 		}
 	}
 
 	return dirCount, scriptCount
 }
 
-// extractDescription extracts the description from script comments
-func extractDescription(scriptPath string) string {
-	file, err := os.Open(scriptPath)
-	if err != nil {
-		return "Sin descripción"
+// isHiddenFolder reports whether a folder is excluded by hidden_folders (lib by default)
+func isHiddenFolder(name string) bool {
+	for _, hidden := range config.Current().HiddenFolders {
+		if strings.EqualFold(name, hidden) {
+			return true
+		}
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	
-	// Read first 5 lines
-	for scanner.Scan() && lineCount < 5 {
-		line := strings.TrimSpace(scanner.Text())
-		lineCount++
+	return false
+}
 
-		// Skip shebang and empty lines
-		if strings.HasPrefix(line, "#!") || line == "" {
-			continue
+// isIgnoredFile reports whether a file matches ignore_prefixes (example_ by default)
+func isIgnoredFile(name string) bool {
+	for _, prefix := range config.Current().IgnorePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Look for comment with description
-		if strings.HasPrefix(line, "#") {
-			// Remove comment marker
-			desc := strings.TrimPrefix(line, "#")
-			desc = strings.TrimSpace(desc)
-			
-			// Remove common prefixes
-			desc = strings.TrimPrefix(desc, "Script:")
-			desc = strings.TrimPrefix(desc, "Script para")
-			desc = strings.TrimPrefix(desc, "Descripción:")
-			desc = strings.TrimPrefix(desc, "Description:")
-			desc = strings.TrimSpace(desc)
-
-			if desc != "" {
-				return desc
-			}
+// isScriptFile reports whether a file is listed as a script: its extension,
+// or its whole name for entries like Makefile, is in the extensions setting
+func isScriptFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, allowed := range config.Current().Extensions {
+		if name == allowed || (ext != "" && strings.EqualFold(ext, allowed)) {
+			return true
 		}
 	}
+	return false
+}
+
+// isScriptEntry reports whether a file is listed as a script: by extension,
+// or as an executable with a #! line when detect_executables is on
+func isScriptEntry(path, name string) bool {
+	if isScriptFile(name) {
+		return true
+	}
+	return config.Current().DetectExecutables && runsDirectly(path)
+}
+
+// runsDirectly reports whether a file can be executed as is: it has the
+// executable bit and a #! line. Never true on Windows.
+func runsDirectly(path string) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return false
+	}
+	return interpreter.ReadShebang(path) != ""
+}
+
+// extractDescription extracts the description from script comments
+func extractDescription(scriptPath string, meta ScriptMeta) string {
+	if desc := strings.TrimSpace(meta.Description); desc != "" {
+		return desc
+	}
+
+	// Legacy header: first comment line, without the usual prefixes
+	desc := meta.summary
+	desc = strings.TrimPrefix(desc, "Script:")
+	desc = strings.TrimPrefix(desc, "Script para")
+	desc = strings.TrimPrefix(desc, "Descripción:")
+	desc = strings.TrimPrefix(desc, "Description:")
+	desc = strings.TrimSpace(desc)
+	if desc != "" {
+		return desc
+	}
 
 	// Fallback: use filename
 	name := filepath.Base(scriptPath)
@@ -171,3 +224,53 @@ func extractDescription(scriptPath string) string {
 	name = strings.ReplaceAll(name, "_", " ")
 	return name
 }
+
+// DisplayName returns the header @name when declared, or the file name
+func (s Script) DisplayName() string {
+	if strings.TrimSpace(s.Title) != "" {
+		return s.Title
+	}
+	return s.Name
+}
+
+// newScript builds a Script for a file, reading its header block
+func newScript(path, ext string) Script {
+	meta, _ := ParseScriptMeta(path)
+
+	var interpreterName string
+	if in, ok := interpreters().Resolve(path); ok {
+		interpreterName = in.Name
+	}
+	direct := runsDirectly(path)
+	if direct && interpreterName == "" {
+		interpreterName = interpreter.ShebangProgram(interpreter.ReadShebang(path))
+	}
+
+	script := Script{
+		Name:        filepath.Base(path),
+		Path:        path,
+		Description: extractDescription(path, meta),
+		Extension:   ext,
+		Interpreter: interpreterName,
+		Direct:      direct,
+		Title:       meta.Title,
+		Tags:        meta.Tags,
+		Requires:    meta.Requires,
+		RequiresEnv: meta.RequiresEnv,
+		RequiresOS:  meta.RequiresOS,
+		MinLauncher: meta.MinLauncher,
+		Root:        meta.Root,
+		Sudo:        meta.Sudo,
+		Confirm:     meta.Confirm,
+		Dangerous:   meta.Dangerous,
+		Timeout:     meta.Timeout,
+		Mode:        meta.Mode,
+		Cwd:         meta.Cwd,
+		Args:        meta.Args,
+		Params:      meta.Params,
+	}
+	if script.hasRequirements() {
+		script.Unmet = unmetRequirements(checkRequirements(script))
+	}
+	return script
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/lucas/launcher/config"
+)
+
+func TestScanScriptsExecutables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no executable bit on Windows")
+	}
+	dir := t.TempDir()
+	for _, file := range []struct {
+		name, content string
+		perm          os.FileMode
+	}{
+		{"deploy", "#!/usr/bin/env python3\nprint(1)\n", 0755},
+		{"report", "#!/usr/bin/perl\nprint 1;\n", 0755},
+		{"build.sh", "echo build\n", 0644},
+		{"notes", "#!/bin/sh\necho notes\n", 0644},
+		{"data.bin", "\x7fELF", 0755},
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file.name), []byte(file.content), file.perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := config.Current()
+	defer config.SetCurrent(previous)
+
+	type listed struct {
+		interpreter string
+		direct      bool
+	}
+	scan := func() map[string]listed {
+		t.Helper()
+		scripts, err := ScanScripts(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]listed{}
+		for _, s := range scripts {
+			got[s.Name] = listed{s.Interpreter, s.Direct}
+		}
+		return got
+	}
+
+	config.SetCurrent(config.Default())
+	got := scan()
+	want := map[string]listed{
+		"deploy":   {"python", true},
+		"report":   {"perl", true},
+		"build.sh": {"bash", false},
+	}
+	if len(got) != len(want) {
+		t.Errorf("ScanScripts listed %v, want %v", got, want)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %+v, want %+v", name, got[name], w)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.DetectExecutables = false
+	config.SetCurrent(cfg)
+	if got := scan(); len(got) != 1 || got["build.sh"] != (listed{"bash", false}) {
+		t.Errorf("with detect_executables off ScanScripts listed %v", got)
+	}
+}
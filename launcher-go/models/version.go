@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lucas/launcher/semver"
+)
+
+// LauncherVersion reads the version of the launcher from VERSION.txt in
+// rootDir. The first word must be a SemVer 2.0 version ("v0.4.6",
+// "v0.5.0-rc.1").
+func LauncherVersion(rootDir string) (semver.Version, error) {
+	raw := readLauncherVersion(rootDir)
+	if raw == "" {
+		return semver.Version{}, fmt.Errorf("no se encontró VERSION.txt en %s", rootDir)
+	}
+	return semver.Parse(raw)
+}
+
+var (
+	launcherVersionOnce sync.Once
+	launcherVersionData semver.Version
+	launcherVersionErr  error
+)
+
+// currentLauncherVersion is LauncherVersion of the running launcher, read once
+func currentLauncherVersion() (semver.Version, error) {
+	launcherVersionOnce.Do(func() {
+		launcherVersionData, launcherVersionErr = LauncherVersion(FindRootDir())
+	})
+	return launcherVersionData, launcherVersionErr
+}
+
+// checkLauncherVersion evaluates @requires-launcher against this launcher
+func checkLauncherVersion(minimum string) requirementCheck {
+	check := requirementCheck{Label: "launcher >= " + minimum}
+	required, err := semver.Parse(minimum)
+	if err != nil {
+		check.Detail = "@requires-launcher: " + err.Error()
+		return check
+	}
+	current, err := currentLauncherVersion()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = current.Compare(required) >= 0
+	check.Detail = current.String()
+	if !check.OK {
+		check.Detail += " (actualiza DevLauncher)"
+	}
+	return check
+}
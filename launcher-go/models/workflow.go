@@ -0,0 +1,570 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucas/launcher/config"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowSuffixes are the file name endings listed as workflows next to scripts
+var workflowSuffixes = []string{".workflow.yaml", ".workflow.yml"}
+
+// Workflow is a chain of scripts declared in a <name>.workflow.yaml file:
+//
+//	name: Entorno web
+//	description: Go, Node.js y pnpm
+//	steps:
+//	  - script: instalar_go.sh
+//	    unless_command: go
+//	  - id: node
+//	    script: instalar_nodejs.sh
+//	    args: [--lts]
+//	  - script: instalar_pnpm.sh
+//	    needs: [node]
+//	    continue_on_error: true
+//
+// Steps run in file order, or in dependency order when some declare needs.
+// A failed step stops the workflow unless it continues on error; steps with
+// if: failure or if: always still run afterwards.
+type Workflow struct {
+	Name            string         `yaml:"name"`
+	Description     string         `yaml:"description"`
+	ContinueOnError bool           `yaml:"continue_on_error"` // Default for every step
+	Confirm         bool           `yaml:"confirm"`
+	Mode            string         `yaml:"mode"` // terminal or capture for every step; empty keeps each script's mode
+	Steps           []WorkflowStep `yaml:"steps"`
+}
+
+// WorkflowStep runs one script of a workflow
+type WorkflowStep struct {
+	ID              string            `yaml:"id"` // Defaults to the script name without extension
+	Name            string            `yaml:"name"`
+	Script          string            `yaml:"script"` // Relative to the workflow folder, or categoria/ruta/script
+	Args            []string          `yaml:"args"`
+	Env             map[string]string `yaml:"env"`
+	Needs           []string          `yaml:"needs"`
+	ContinueOnError *bool             `yaml:"continue_on_error"`
+	If              string            `yaml:"if"`             // success (default), failure or always
+	OS              []string          `yaml:"os"`             // Same syntax as @requires-os
+	UnlessCommand   string            `yaml:"unless_command"` // Skipped when this command is in the PATH
+}
+
+// Step conditions (the if key)
+const (
+	StepIfSuccess = "success"
+	StepIfFailure = "failure"
+	StepIfAlways  = "always"
+)
+
+// Step statuses besides RunOK, RunFailed, RunCancelled and RunTimedOut
+const (
+	stepPending = "pending"
+	stepRunning = "running"
+	stepSkipped = "skipped" // Its condition did not apply; counts as success
+	stepNotRun  = "not-run" // Not run because of a failure or a cancellation
+)
+
+// workflowSuffix returns the workflow ending of a file name, or ""
+func workflowSuffix(name string) string {
+	lower := strings.ToLower(name)
+	for _, suffix := range workflowSuffixes {
+		if strings.HasSuffix(lower, suffix) && len(name) > len(suffix) {
+			return name[len(name)-len(suffix):]
+		}
+	}
+	return ""
+}
+
+// IsWorkflow reports whether the item is a workflow file
+func (s Script) IsWorkflow() bool {
+	return s.Extension != ".dir" && workflowSuffix(s.Name) != ""
+}
+
+// LoadWorkflow reads and validates a workflow file
+func LoadWorkflow(file string) (Workflow, error) {
+	var wf Workflow
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return wf, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&wf); err != nil && !errors.Is(err, io.EOF) {
+		return wf, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+
+	if len(wf.Steps) == 0 {
+		return wf, fmt.Errorf("%s: no tiene pasos (steps)", filepath.Base(file))
+	}
+	switch wf.Mode {
+	case "", config.RunModeTerminal, config.RunModeCapture:
+	default:
+		return wf, fmt.Errorf("%s: mode %q debe ser %s o %s", filepath.Base(file), wf.Mode, config.RunModeTerminal, config.RunModeCapture)
+	}
+
+	ids := map[string]bool{}
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if strings.TrimSpace(step.Script) == "" {
+			return wf, fmt.Errorf("%s: el paso %d no indica script", filepath.Base(file), i+1)
+		}
+		if step.ID == "" {
+			base := path.Base(filepath.ToSlash(step.Script))
+			step.ID = strings.TrimSuffix(base, path.Ext(base))
+		}
+		if ids[step.ID] {
+			return wf, fmt.Errorf("%s: id de paso repetido: %s (usa id: para distinguirlos)", filepath.Base(file), step.ID)
+		}
+		ids[step.ID] = true
+		switch step.If {
+		case "", StepIfSuccess, StepIfFailure, StepIfAlways:
+		default:
+			return wf, fmt.Errorf("%s: paso %s: if %q debe ser %s, %s o %s", filepath.Base(file), step.ID, step.If, StepIfSuccess, StepIfFailure, StepIfAlways)
+		}
+	}
+	for _, step := range wf.Steps {
+		for _, need := range step.Needs {
+			if !ids[need] {
+				return wf, fmt.Errorf("%s: paso %s: needs %q no existe", filepath.Base(file), step.ID, need)
+			}
+		}
+	}
+	if _, err := workflowOrder(wf); err != nil {
+		return wf, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	return wf, nil
+}
+
+// workflowOrder returns the indexes of the steps in execution order: file
+// order, moved only as much as needs requires
+func workflowOrder(wf Workflow) ([]int, error) {
+	index := map[string]int{}
+	for i, step := range wf.Steps {
+		index[step.ID] = i
+	}
+
+	done := make([]bool, len(wf.Steps))
+	order := make([]int, 0, len(wf.Steps))
+	for len(order) < len(wf.Steps) {
+		progress := false
+		for i, step := range wf.Steps {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, need := range step.Needs {
+				if !done[index[need]] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[i] = true
+				order = append(order, i)
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			var cycle []string
+			for i, step := range wf.Steps {
+				if !done[i] {
+					cycle = append(cycle, step.ID)
+				}
+			}
+			return nil, fmt.Errorf("dependencia circular entre: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return order, nil
+}
+
+// newWorkflowScript builds the list item of a workflow file
+func newWorkflowScript(file, suffix string) Script {
+	script := Script{
+		Name:      filepath.Base(file),
+		Path:      file,
+		Extension: suffix,
+		Icon:      "🔗",
+	}
+	wf, err := LoadWorkflow(file)
+	if err != nil {
+		script.Description = "✗ " + err.Error()
+		return script
+	}
+	script.Title = wf.Name
+	script.Description = wf.Description
+	script.Confirm = wf.Confirm
+	script.ScriptCount = len(wf.Steps)
+	return script
+}
+
+// workflowStepRun is the state of one step while its workflow runs
+type workflowStepRun struct {
+	WorkflowStep
+	script          Script
+	needs           []*workflowStepRun
+	continueOnError bool
+	status          string
+	exitCode        int
+	duration        time.Duration
+	output          string
+	note            string // Why the step was skipped or failed before running
+}
+
+// blocking reports whether the step makes the ones after it (or depending
+// on it) count as failed
+func (s *workflowStepRun) blocking() bool {
+	switch s.status {
+	case stepNotRun:
+		return true
+	case RunFailed, RunCancelled, RunTimedOut:
+		return !s.continueOnError
+	}
+	return false
+}
+
+// Label returns the name shown for the step
+func (s *workflowStepRun) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.script.DisplayName()
+}
+
+// workflowRun executes the steps of a workflow one at a time. The caller
+// asks for the next step, runs it and reports the result with finish.
+type workflowRun struct {
+	workflow  Workflow
+	script    Script // The workflow file as listed
+	runDir    string
+	steps     []*workflowStepRun // In execution order
+	halted    bool               // A step failed without continue_on_error
+	cancelled bool
+}
+
+// planWorkflow loads a workflow and resolves the script of every step
+func planWorkflow(categories []Category, script Script, runDir string) (*workflowRun, error) {
+	wf, err := LoadWorkflow(script.Path)
+	if err != nil {
+		return nil, err
+	}
+	order, _ := workflowOrder(wf)
+
+	w := &workflowRun{workflow: wf, script: script, runDir: runDir}
+	byID := map[string]*workflowStepRun{}
+	var errs []string
+	for _, i := range order {
+		step := &workflowStepRun{WorkflowStep: wf.Steps[i], status: stepPending, continueOnError: wf.ContinueOnError}
+		if step.WorkflowStep.ContinueOnError != nil {
+			step.continueOnError = *step.WorkflowStep.ContinueOnError
+		}
+		for _, need := range step.Needs {
+			step.needs = append(step.needs, byID[need])
+		}
+		resolved, err := resolveStepScript(categories, script.Path, step.Script)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("paso %s: %v", step.ID, err))
+		}
+		step.script = resolved
+		if wf.Mode != "" {
+			step.script.Mode = wf.Mode
+		}
+		byID[step.ID] = step
+		w.steps = append(w.steps, step)
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return w, nil
+}
+
+// resolveStepScript finds the script of a step: a path relative to the
+// workflow folder (in any root of the merged folder) or a logical
+// categoria/ruta/script path. The extension is optional.
+func resolveStepScript(categories []Category, workflowPath, ref string) (Script, error) {
+	ref = filepath.FromSlash(strings.TrimSpace(ref))
+	dir := filepath.Dir(workflowPath)
+
+	var candidates []string
+	if filepath.IsAbs(ref) {
+		candidates = append(candidates, ref)
+	} else {
+		candidates = append(candidates, filepath.Join(dir, ref))
+		for _, cat := range categories {
+			if cat.Favorites {
+				continue
+			}
+			if rel, ok := folderRel(cat, dir); ok {
+				for _, source := range folderSources(cat, rel) {
+					candidates = append(candidates, filepath.Join(source.Path, ref))
+				}
+			}
+			if rest, ok := strings.CutPrefix(filepath.ToSlash(ref), cat.Name+"/"); ok {
+				for _, source := range cat.Sources {
+					candidates = append(candidates, filepath.Join(source.Path, filepath.FromSlash(rest)))
+				}
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, file := range withExtensions(candidate) {
+			if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+				if workflowSuffix(file) != "" {
+					return Script{}, fmt.Errorf("un workflow no puede ejecutar otro workflow: %s", ref)
+				}
+				return withEnvContext(categories, newScript(file, filepath.Ext(file))), nil
+			}
+		}
+	}
+	return Script{}, fmt.Errorf("script no encontrado: %s", filepath.ToSlash(ref))
+}
+
+// withExtensions returns file, plus file with every listed extension when
+// it has none
+func withExtensions(file string) []string {
+	files := []string{file}
+	if filepath.Ext(file) == "" {
+		for _, ext := range config.Current().Extensions {
+			if strings.HasPrefix(ext, ".") {
+				files = append(files, file+ext)
+			}
+		}
+	}
+	return files
+}
+
+// next returns the index of the next step to run, or -1 when the workflow
+// is over. Steps whose condition does not hold are marked on the way.
+func (w *workflowRun) next() int {
+	for i, step := range w.steps {
+		if step.status != stepPending {
+			continue
+		}
+		if w.cancelled {
+			step.status, step.note = stepNotRun, "cancelado"
+			continue
+		}
+
+		failed := w.halted
+		deps := step.needs
+		if len(deps) == 0 {
+			deps = w.steps[:i]
+		}
+		var failedDep string
+		for _, dep := range deps {
+			if dep.blocking() {
+				failed = true
+				if failedDep == "" {
+					failedDep = dep.ID
+				}
+			}
+		}
+
+		switch step.If {
+		case StepIfAlways:
+		case StepIfFailure:
+			if !failed {
+				step.status, step.note = stepSkipped, "solo si falla un paso anterior"
+				continue
+			}
+		default:
+			if failed {
+				step.status, step.note = stepNotRun, "workflow detenido"
+				if failedDep != "" {
+					step.note = "falló " + failedDep
+				}
+				continue
+			}
+		}
+
+		if len(step.OS) > 0 {
+			matches := false
+			for _, spec := range step.OS {
+				matches = matches || currentOS().Satisfies(spec)
+			}
+			if !matches {
+				step.status, step.note = stepSkipped, "solo en "+strings.Join(step.OS, ", ")
+				continue
+			}
+		}
+		if step.UnlessCommand != "" {
+			if found, err := exec.LookPath(step.UnlessCommand); err == nil {
+				step.status, step.note = stepSkipped, step.UnlessCommand+" ya está instalado ("+found+")"
+				continue
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+// finish records the result of the step at index i
+func (w *workflowRun) finish(i int, status string, exitCode int, duration time.Duration, output string) {
+	step := w.steps[i]
+	step.status = status
+	step.exitCode = exitCode
+	step.duration = duration
+	step.output = output
+	if status == RunCancelled {
+		w.cancelled = true
+	}
+	if step.blocking() {
+		w.halted = true
+	}
+}
+
+// stepOptions returns the arguments and environment of a step
+func (w *workflowRun) stepOptions(step *workflowStepRun) RunOptions {
+	opts := RunOptions{Args: append([]string(nil), step.Args...)}
+	keys := make([]string, 0, len(step.Env))
+	for key := range step.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		opts.Env = append(opts.Env, key+"="+step.Env[key])
+	}
+	return opts
+}
+
+// Status returns RunOK, RunFailed or RunCancelled for the whole workflow
+func (w *workflowRun) Status() string {
+	if w.cancelled {
+		return RunCancelled
+	}
+	for _, step := range w.steps {
+		if step.blocking() {
+			return RunFailed
+		}
+	}
+	return RunOK
+}
+
+// ExitCode returns the exit code of the first step that failed the
+// workflow, 124 when it timed out, or 0
+func (w *workflowRun) ExitCode() int {
+	for _, step := range w.steps {
+		if !step.blocking() || step.status == stepNotRun {
+			continue
+		}
+		if step.status == RunTimedOut {
+			return 124
+		}
+		if step.exitCode != 0 {
+			return step.exitCode
+		}
+		return 1
+	}
+	if w.cancelled {
+		return 130
+	}
+	return 0
+}
+
+// gateScript returns the workflow as a script for the confirmation gate:
+// dangerous or @confirm when any of its steps is, described by its steps
+func (w *workflowRun) gateScript(categories []Category) Script {
+	script := w.script
+	script.Confirm = script.Confirm || w.workflow.Confirm
+	names := make([]string, 0, len(w.steps))
+	for _, step := range w.steps {
+		names = append(names, step.script.Name)
+		script.Confirm = script.Confirm || step.script.Confirm
+		if isDangerous(step.script, scriptLogicalPath(categories, step.script)) {
+			script.Dangerous = true
+		}
+	}
+	script.Description = "Pasos: " + strings.Join(names, " → ")
+	return script
+}
+
+// stepMark returns the symbol of a step status
+func stepMark(status string) string {
+	switch status {
+	case stepPending:
+		return "○"
+	case stepRunning:
+		return "▶"
+	case RunOK:
+		return "✓"
+	case stepSkipped:
+		return "↷"
+	case stepNotRun:
+		return "·"
+	case RunCancelled:
+		return "⊘"
+	case RunTimedOut:
+		return "⏱"
+	}
+	return "✗"
+}
+
+// runCLIWorkflow runs a workflow for "launcher run", every step attached
+// to the terminal, and returns the exit code of the first failed step
+func runCLIWorkflow(script Script, logical, runDir string, categories []Category, skipChecks, assumeYes bool, historyPath string) int {
+	w, err := planWorkflow(categories, script, runDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if !assumeYes && !confirmCLI(w.gateScript(categories), logical, runDir, RunOptions{}) {
+		return 4
+	}
+
+	for i := w.next(); i >= 0; i = w.next() {
+		step := w.steps[i]
+		fmt.Fprintf(os.Stderr, "\n▶ [%d/%d] %s\n", i+1, len(w.steps), step.Label())
+
+		if !skipChecks && step.script.hasRequirements() {
+			if unmet := unmetRequirements(checkRequirements(step.script)); len(unmet) > 0 {
+				step.note = "falta: " + strings.Join(unmet, ", ")
+				fmt.Fprintln(os.Stderr, "✗ "+step.note)
+				w.finish(i, RunFailed, 3, 0, "")
+				continue
+			}
+		}
+
+		run, err := newScriptRun(step.script, runDir, w.stepOptions(step))
+		if err != nil {
+			step.note = err.Error()
+			fmt.Fprintln(os.Stderr, "✗ "+step.note)
+			w.finish(i, RunFailed, 1, 0, "")
+			continue
+		}
+		run.SetStdin(os.Stdin)
+		run.SetStdout(os.Stdout)
+		run.SetStderr(os.Stderr)
+		exitCode, output := run.Result(run.Run())
+		if err := AppendHistory(historyPath, run.HistoryEntry(exitCode, output, SourceWorkflow)); err != nil {
+			fmt.Fprintln(os.Stderr, "No se pudo guardar el historial:", err)
+		}
+		w.finish(i, run.Status(), exitCode, run.Elapsed(), output)
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%s:\n", script.DisplayName())
+	for _, step := range w.steps {
+		line := fmt.Sprintf("  %s %-32s", stepMark(step.status), step.Label())
+		if step.duration > 0 {
+			line += " " + formatDuration(step.duration)
+		}
+		if step.note != "" {
+			line += "  (" + step.note + ")"
+		} else if step.continueOnError && (step.status == RunFailed || step.status == RunTimedOut) {
+			line += "  (continúa)"
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	return w.ExitCode()
+}
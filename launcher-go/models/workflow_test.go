@@ -0,0 +1,167 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeWorkflow(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "setup.workflow.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWorkflowSuffix(t *testing.T) {
+	for name, want := range map[string]string{
+		"web.workflow.yaml": ".workflow.yaml",
+		"Web.Workflow.YML":  ".Workflow.YML",
+		".workflow.yaml":    "",
+		"workflow.yaml":     "",
+		"web.yaml":          "",
+	} {
+		if got := workflowSuffix(name); got != want {
+			t.Errorf("workflowSuffix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadWorkflow(t *testing.T) {
+	path := writeWorkflow(t, t.TempDir(), `
+name: Entorno web
+steps:
+  - script: instalar_go.sh
+  - id: node
+    script: tools/instalar_nodejs.sh
+    args: [--lts]
+  - script: instalar_pnpm
+    needs: [node]
+    if: always
+`)
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, step := range wf.Steps {
+		ids = append(ids, step.ID)
+	}
+	if want := []string{"instalar_go", "node", "instalar_pnpm"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+	if wf.Name != "Entorno web" || !reflect.DeepEqual(wf.Steps[1].Args, []string{"--lts"}) {
+		t.Errorf("LoadWorkflow = %+v", wf)
+	}
+}
+
+func TestLoadWorkflowInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no steps":        "name: vacío\n",
+		"step without":    "steps:\n  - args: [x]\n",
+		"repeated id":     "steps:\n  - script: a.sh\n  - script: other/a.sh\n",
+		"unknown need":    "steps:\n  - script: a.sh\n    needs: [b]\n",
+		"bad condition":   "steps:\n  - script: a.sh\n    if: sometimes\n",
+		"bad mode":        "mode: fast\nsteps:\n  - script: a.sh\n",
+		"unknown key":     "steps:\n  - script: a.sh\n    retries: 3\n",
+		"dependency loop": "steps:\n  - script: a.sh\n    needs: [b]\n  - script: b.sh\n    needs: [a]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadWorkflow(writeWorkflow(t, t.TempDir(), content)); err == nil {
+				t.Error("LoadWorkflow accepted an invalid workflow")
+			}
+		})
+	}
+}
+
+func TestWorkflowOrder(t *testing.T) {
+	wf := Workflow{Steps: []WorkflowStep{
+		{ID: "a"},
+		{ID: "b", Needs: []string{"d"}},
+		{ID: "c"},
+		{ID: "d", Needs: []string{"a"}},
+	}}
+	order, err := workflowOrder(wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 2, 3, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("workflowOrder = %v, want %v", order, want)
+	}
+}
+
+func TestWorkflowRunConditions(t *testing.T) {
+	dir := t.TempDir()
+	ext := testScriptExt()
+	for _, name := range []string{"build", "lint", "test", "report", "cleanup"} {
+		makeTree(t, dir, name+ext)
+	}
+	path := writeWorkflow(t, dir, `
+steps:
+  - script: build
+  - script: lint
+    continue_on_error: true
+  - script: test
+  - script: report
+    if: failure
+  - script: cleanup
+    if: always
+`)
+	w, err := planWorkflow(nil, Script{Name: filepath.Base(path), Path: path}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(want string, status string, exitCode int) {
+		t.Helper()
+		i := w.next()
+		if i < 0 || w.steps[i].ID != want {
+			t.Fatalf("next step = %d, want %s", i, want)
+		}
+		w.finish(i, status, exitCode, 0, "")
+	}
+	run("build", RunOK, 0)
+	run("lint", RunFailed, 1)
+	run("test", RunFailed, 2)
+	run("report", RunOK, 0)
+	run("cleanup", RunOK, 0)
+	if i := w.next(); i != -1 {
+		t.Errorf("next = %d after the last step", i)
+	}
+	if w.Status() != RunFailed || w.ExitCode() != 2 {
+		t.Errorf("workflow = %s, exit %d; want failed with the exit code of test", w.Status(), w.ExitCode())
+	}
+}
+
+func TestWorkflowRunStopsAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	ext := testScriptExt()
+	makeTree(t, dir, "a"+ext, "b"+ext, "c"+ext)
+	path := writeWorkflow(t, dir, "steps:\n  - script: a\n  - script: b\n  - script: c\n    if: failure\n")
+	w, err := planWorkflow(nil, Script{Name: filepath.Base(path), Path: path}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.finish(w.next(), RunFailed, 7, 0, "")
+	if i := w.next(); i < 0 || w.steps[i].ID != "c" {
+		t.Fatalf("next = %d, want the if: failure step", i)
+	}
+	if b := w.steps[1]; b.status != stepNotRun || !strings.Contains(b.note, "a") {
+		t.Errorf("b = %s (%s), want not run because a failed", b.status, b.note)
+	}
+}
+
+func TestPlanWorkflowMissingScript(t *testing.T) {
+	dir := t.TempDir()
+	path := writeWorkflow(t, dir, "steps:\n  - script: missing\n")
+	_, err := planWorkflow(nil, Script{Name: filepath.Base(path), Path: path}, dir)
+	if err == nil || !strings.Contains(err.Error(), "script no encontrado: missing") {
+		t.Errorf("planWorkflow = %v, want the missing script", err)
+	}
+}
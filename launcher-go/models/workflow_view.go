@@ -0,0 +1,264 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lucas/launcher/config"
+	"github.com/lucas/launcher/ui"
+)
+
+type workflowStepMsg struct {
+	index    int
+	exitCode int
+	output   string
+	entry    HistoryEntry
+}
+
+// startWorkflow switches to WorkflowView and runs the first step
+func (m *Model) startWorkflow(script Script, runDir string) tea.Cmd {
+	if m.state != WorkflowView {
+		m.workflowReturn = m.state
+	}
+	m.currentScript = script
+	m.workflowIndex = 0
+	m.workflowActive = nil
+	m.workflow, m.workflowErr = planWorkflow(m.categories, script, runDir)
+	m.state = WorkflowView
+	if m.workflowErr != nil {
+		return nil
+	}
+	return m.runNextStep()
+}
+
+// runNextStep starts the next step of the workflow. Steps with unmet
+// requirements fail without running; captured steps run like @mode capture
+// and the others take over the terminal.
+func (m *Model) runNextStep() tea.Cmd {
+	w := m.workflow
+	for {
+		i := w.next()
+		if i < 0 {
+			m.workflowActive = nil
+			return nil
+		}
+		step := w.steps[i]
+		m.workflowIndex = i
+
+		if step.script.hasRequirements() {
+			if unmet := unmetRequirements(checkRequirements(step.script)); len(unmet) > 0 {
+				step.note = "falta: " + strings.Join(unmet, ", ")
+				w.finish(i, RunFailed, 3, 0, "")
+				continue
+			}
+		}
+
+		run, err := newScriptRun(step.script, w.runDir, w.stepOptions(step))
+		if err != nil {
+			step.note = err.Error()
+			w.finish(i, RunFailed, 1, 0, "")
+			continue
+		}
+		step.status = stepRunning
+		m.workflowActive = run
+
+		finished := func(err error) tea.Msg {
+			exitCode, output := run.Result(err)
+			return workflowStepMsg{index: i, exitCode: exitCode, output: output, entry: run.HistoryEntry(exitCode, output, SourceWorkflow)}
+		}
+		if runMode(step.script) == config.RunModeTerminal {
+			return tea.Exec(run, finished)
+		}
+		run.SetStdout(io.Discard)
+		run.SetStderr(io.Discard)
+		return tea.Batch(func() tea.Msg { return finished(run.Run()) }, execTick())
+	}
+}
+
+// finishWorkflowStep records a finished step and starts the next one
+func (m *Model) finishWorkflowStep(msg workflowStepMsg) tea.Cmd {
+	m.workflowActive = nil
+	if m.workflow == nil || msg.index >= len(m.workflow.steps) {
+		return saveHistory(m.historyPath, msg.entry)
+	}
+	m.workflow.finish(msg.index, msg.entry.Status, msg.exitCode, msg.entry.Duration, msg.output)
+	return tea.Batch(saveHistory(m.historyPath, msg.entry), m.runNextStep())
+}
+
+// updateWorkflowView handles keys in WorkflowView. While a step runs only
+// cancellation is accepted; keys it does not use are left to the global
+// handler (command mode, quit).
+func (m *Model) updateWorkflowView(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.workflowActive != nil {
+		switch msg.String() {
+		case "x", "ctrl+c":
+			// Stops the whole workflow, even a step that continues on error
+			m.workflow.cancelled = true
+			m.workflowActive.Cancel()
+		}
+		return nil, true
+	}
+
+	switch msg.String() {
+	case "esc", "0", ".":
+		m.state = m.workflowReturn
+		m.refreshRequirements()
+		return nil, true
+	}
+	if m.workflow == nil {
+		return nil, false
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.workflowIndex > 0 {
+			m.workflowIndex--
+		}
+		return nil, true
+	case "down", "j":
+		if m.workflowIndex < len(m.workflow.steps)-1 {
+			m.workflowIndex++
+		}
+		return nil, true
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if num := int(msg.String()[0]-'0') - 1; num < len(m.workflow.steps) {
+			m.workflowIndex = num
+		}
+		return nil, true
+	case "enter":
+		// Full output of the selected step
+		step := m.workflow.steps[m.workflowIndex]
+		switch step.status {
+		case RunOK, RunFailed, RunCancelled, RunTimedOut:
+			m.currentScript = step.script
+			m.executionResult = step.exitCode
+			m.executionStatus = step.status
+			m.executionTime = step.duration
+			m.executionOutput = step.output
+			if step.note != "" {
+				m.executionOutput = step.note + "\n" + step.output
+			}
+			m.showResult()
+			m.returnState = WorkflowView
+			m.state = ResultView
+		}
+		return nil, true
+	case "r":
+		return m.runScriptIn(m.workflow.script, m.workflow.runDir, RunOptions{}), true
+	}
+	return nil, false
+}
+
+func (m Model) renderWorkflowView() string {
+	title := m.currentScript.DisplayName()
+	if m.workflow != nil {
+		title = m.workflow.script.DisplayName()
+	}
+	content := ui.RenderBreadcrumb([]string{"Inicio", m.currentCategory.Name, title}, m.runDir)
+	content += ui.TitleStyle.Render("🔗 Workflow: "+title) + "\n"
+
+	if m.workflowErr != nil {
+		content += "\n" + ui.ErrorStyle.Render("✗ No se puede ejecutar el workflow:") + "\n"
+		for _, line := range strings.Split(m.workflowErr.Error(), "\n") {
+			content += "  " + line + "\n"
+		}
+		content += "\n" + ui.DimStyle.Render("/: buscar  esc/./0: volver  q: salir")
+		return content
+	}
+
+	w := m.workflow
+	if w.workflow.Description != "" {
+		content += ui.DimStyle.Render(w.workflow.Description) + "\n"
+	}
+	content += "\n"
+
+	for i, step := range w.steps {
+		mark := stepMark(step.status)
+		switch step.status {
+		case RunOK, stepRunning:
+			mark = ui.SuccessStyle.Render(mark)
+		case RunFailed, RunCancelled, RunTimedOut:
+			mark = ui.ErrorStyle.Render(mark)
+		default:
+			mark = ui.DimStyle.Render(mark)
+		}
+
+		duration := ""
+		switch {
+		case step.status == stepRunning && m.workflowActive != nil:
+			duration = formatDuration(m.workflowActive.Elapsed())
+		case step.duration > 0:
+			duration = formatDuration(step.duration)
+		}
+		line := fmt.Sprintf("[%d] %-32s %8s", i+1, step.Label(), duration)
+		if i == m.workflowIndex {
+			line = ui.SelectedExecutableStyle.Render(line)
+		} else {
+			line = ui.ExecutableStyle.Render(line)
+		}
+		content += "  " + mark + " " + line
+		if step.note != "" {
+			content += "  " + ui.DimStyle.Render(step.note)
+		} else if step.continueOnError && (step.status == RunFailed || step.status == RunTimedOut) {
+			content += "  " + ui.DimStyle.Render("(continúa)")
+		}
+		content += "\n"
+	}
+
+	content += "\n"
+	if m.workflowActive == nil {
+		switch w.Status() {
+		case RunOK:
+			content += ui.SuccessStyle.Render(fmt.Sprintf("✓ Workflow completado en %s", formatDuration(workflowDuration(w)))) + "\n"
+		case RunCancelled:
+			content += ui.ErrorStyle.Render("⊘ Workflow cancelado") + "\n"
+		default:
+			content += ui.ErrorStyle.Render(fmt.Sprintf("✗ Workflow fallido (exit code: %d)", w.ExitCode())) + "\n"
+		}
+	}
+
+	if m.workflowIndex < len(w.steps) {
+		step := w.steps[m.workflowIndex]
+		var lines []string
+		visible := m.height - len(w.steps) - 14
+		if visible < 5 {
+			visible = 5
+		}
+		if step.status == stepRunning && m.workflowActive != nil {
+			lines = m.workflowActive.output.Tail(visible)
+		} else if step.output != "" {
+			lines = strings.Split(strings.TrimRight(step.output, "\n"), "\n")
+			if len(lines) > visible {
+				lines = lines[len(lines)-visible:]
+			}
+		}
+		if len(lines) > 0 {
+			content += ui.DimStyle.Render("── Salida de "+step.Label()+" ──") + "\n"
+			for _, line := range lines {
+				content += line + "\x1b[0m\n"
+			}
+		}
+	}
+
+	if m.workflowActive != nil {
+		content += "\n" + ui.DimStyle.Render("x/ctrl+c: cancelar el workflow")
+	} else {
+		content += "\n" + ui.DimStyle.Render("↑↓/j/k/1-9: elegir paso  enter: salida completa  r: repetir  /: buscar  esc/./0: volver  q: salir")
+	}
+
+	if m.commandMode.active {
+		content += m.commandMode.View()
+	}
+	return content
+}
+
+// workflowDuration adds up the time spent in the steps
+func workflowDuration(w *workflowRun) (total time.Duration) {
+	for _, step := range w.steps {
+		total += step.duration
+	}
+	return total
+}
@@ -0,0 +1,178 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"V0.0.0", Version{}},
+		{"  v0.4.6\n", Version{Minor: 4, Patch: 6}},
+		{"1.0.0-rc.1", Version{Major: 1, Prerelease: []string{"rc", "1"}}},
+		{"1.0.0-alpha-1.0", Version{Major: 1, Prerelease: []string{"alpha-1", "0"}}},
+		{"1.0.0-0a.00a", Version{Major: 1, Prerelease: []string{"0a", "00a"}}},
+		{"2.0.0+20260220", Version{Major: 2, Build: []string{"20260220"}}},
+		{"1.0.0-beta+exp.sha.5114f85", Version{Major: 1, Prerelease: []string{"beta"}, Build: []string{"exp", "sha", "5114f85"}}},
+		{"1.0.0+001", Version{Major: 1, Build: []string{"001"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"v",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.02.3",
+		"1.2.-3",
+		"1.2.x",
+		"vv1.2.3",
+		"1.0.0-",
+		"1.0.0-rc..1",
+		"1.0.0-01",
+		"1.0.0-rc_1",
+		"1.0.0+",
+		"1.0.0+a..b",
+		"99999999999999999999.0.0",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if v, err := Parse(in); err == nil {
+				t.Errorf("Parse(%q) = %+v, want an error", in, v)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	for in, want := range map[string]string{
+		"1.2.3":              "v1.2.3",
+		"v1.0.0-rc.1":        "v1.0.0-rc.1",
+		"1.0.0-beta+exp.sha": "v1.0.0-beta+exp.sha",
+	} {
+		v, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.0.0", "2.1.0", -1},
+		{"2.1.0", "2.1.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0+a", "1.0.0+b", 0},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got, err := Compare(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareInvalid(t *testing.T) {
+	if _, err := Compare("1.0", "1.0.0"); err == nil {
+		t.Error("Compare accepted an invalid version")
+	}
+	if _, err := Compare("1.0.0", "x"); err == nil {
+		t.Error("Compare accepted an invalid version")
+	}
+}
+
+// TestSpecPrecedence checks the ordering example of semver.org §11
+func TestSpecPrecedence(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			got, err := Compare(ordered[i], ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"22.04", Version{Major: 22, Minor: 4}},
+		{"13", Version{Major: 13}},
+		{"10.0.22000", Version{Major: 10, Patch: 22000}},
+		{" 14.2.1 ", Version{Major: 14, Minor: 2, Patch: 1}},
+	}
+	for _, tt := range tests {
+		got, err := Coerce(tt.in)
+		if err != nil {
+			t.Fatalf("Coerce(%q): %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Coerce(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "rolling", "1.2.3.4", "22.", "v22.04"} {
+		if _, err := Coerce(in); err == nil {
+			t.Errorf("Coerce(%q) accepted an invalid version", in)
+		}
+	}
+}
@@ -56,6 +56,10 @@ var (
 			Foreground(ColorRed).
 			Bold(true)
 
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(ColorYellow).
+			Bold(true)
+
 	BoxStyle = lipgloss.NewStyle().
 			Foreground(ColorCyan)
 
@@ -83,6 +87,16 @@ var (
 	CountStyle = lipgloss.NewStyle().
 			Foreground(ColorDimGray)
 
+	MatchStyle = lipgloss.NewStyle().
+			Foreground(ColorYellow).
+			Bold(true).
+			Underline(true)
+
+	CurrentMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#000000")).
+				Background(ColorYellow).
+				Bold(true)
+
 	HeaderVersionStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#c0392b")).
 			Bold(true)
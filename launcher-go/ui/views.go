@@ -35,7 +35,12 @@ func LoadASCIIArt(staticPath string) string {
 	// Select random file
 	rand.Seed(time.Now().UnixNano())
 	selectedFile := txtFiles[rand.Intn(len(txtFiles))]
-	asciiPath := filepath.Join(staticPath, selectedFile)
+	return LoadASCIIArtFile(staticPath, selectedFile)
+}
+
+// LoadASCIIArtFile loads a specific ASCII art file from static/ and applies gradient
+func LoadASCIIArtFile(staticPath, name string) string {
+	asciiPath := filepath.Join(staticPath, name)
 
 	file, err := os.Open(asciiPath)
 	if err != nil {
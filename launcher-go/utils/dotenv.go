@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvVar is one KEY=VALUE assignment read from a .env file
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// ParseDotEnv reads a .env file: KEY=VALUE lines, optionally prefixed with
+// "export", with # comments and single or double quotes. $VAR and ${VAR} are
+// expanded (except inside single quotes) with the variables defined earlier
+// in the file, then with lookup. Lines setting a key keep rejects are
+// skipped, also for the expansion of later lines; a nil keep accepts all.
+func ParseDotEnv(path string, lookup func(string) (string, bool), keep func(string) bool) ([]EnvVar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	defined := map[string]string{}
+	resolve := func(key string) string {
+		if value, ok := defined[key]; ok {
+			return value
+		}
+		if lookup != nil {
+			value, _ := lookup(key)
+			return value
+		}
+		return ""
+	}
+
+	var vars []EnvVar
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvKey(key) {
+			return vars, fmt.Errorf("%s:%d: se esperaba CLAVE=valor", path, lineNum)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = os.Expand(strings.ReplaceAll(value[1:len(value)-1], `\n`, "\n"), resolve)
+		default:
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+			value = os.Expand(value, resolve)
+		}
+
+		if keep != nil && !keep(key) {
+			continue
+		}
+		defined[key] = value
+		vars = append(vars, EnvVar{Key: key, Value: value})
+	}
+	return vars, scanner.Err()
+}
+
+func validEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
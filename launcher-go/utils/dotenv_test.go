@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeDotEnv(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseDotEnv(t *testing.T) {
+	path := writeDotEnv(t, "\ufeff# comentario\n"+
+		"\n"+
+		"PLAIN=value\n"+
+		"export EXPORTED = spaced \n"+
+		"INLINE=abc # comentario\n"+
+		"HASH=a#b\n"+
+		"SINGLE='$PLAIN # literal'\n"+
+		"DOUBLE=\"${PLAIN}-$HOME_DIR\\nnext\"\n"+
+		"EXPANDED=$PLAIN/bin:$MISSING\n"+
+		"EMPTY=\n")
+	lookup := func(key string) (string, bool) {
+		if key == "HOME_DIR" {
+			return "/home/dev", true
+		}
+		return "", false
+	}
+
+	got, err := ParseDotEnv(path, lookup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EnvVar{
+		{"PLAIN", "value"},
+		{"EXPORTED", "spaced"},
+		{"INLINE", "abc"},
+		{"HASH", "a#b"},
+		{"SINGLE", "$PLAIN # literal"},
+		{"DOUBLE", "value-/home/dev\nnext"},
+		{"EXPANDED", "value/bin:"},
+		{"EMPTY", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDotEnv =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestParseDotEnvInvalid(t *testing.T) {
+	for _, line := range []string{"NOVALUE", "1KEY=x", "MY-KEY=x", "=x"} {
+		path := writeDotEnv(t, "OK=1\n"+line+"\n")
+		vars, err := ParseDotEnv(path, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), ".env:2:") {
+			t.Errorf("ParseDotEnv(%q) error = %v, want one at line 2", line, err)
+		}
+		if len(vars) != 1 || vars[0].Key != "OK" {
+			t.Errorf("ParseDotEnv(%q) = %q, want the lines before the error", line, vars)
+		}
+	}
+	if _, err := ParseDotEnv(filepath.Join(t.TempDir(), ".env"), nil, nil); err == nil {
+		t.Error("ParseDotEnv read a missing file")
+	}
+}
+
+func TestParseDotEnvKeep(t *testing.T) {
+	path := writeDotEnv(t, "NAME=file\nURL=http://$NAME\nOTHER=x\n")
+	lookup := func(key string) (string, bool) {
+		if key == "NAME" {
+			return "shell", true
+		}
+		return "", false
+	}
+	got, err := ParseDotEnv(path, lookup, func(key string) bool { return key != "NAME" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []EnvVar{{"URL", "http://shell"}, {"OTHER", "x"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDotEnv = %q, want %q", got, want)
+	}
+}
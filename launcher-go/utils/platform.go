@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 )
 
 // Platform represents the detected operating system
@@ -36,3 +38,78 @@ func GetScriptsPath(rootDir string) string {
 func GetStaticPath(rootDir string) string {
 	return filepath.Join(rootDir, "static")
 }
+
+// GetUserConfigDir returns the per-user DevLauncher config directory
+// ($XDG_CONFIG_HOME/devlauncher, %AppData%\devlauncher on Windows)
+func GetUserConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "devlauncher")
+}
+
+// GetConfigPath returns the path of the launcher configuration file
+// (DEVLAUNCHER_CONFIG overrides the default location)
+func GetConfigPath() string {
+	if path := os.Getenv("DEVLAUNCHER_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(GetUserConfigDir(), "config.yaml")
+}
+
+// GetHistoryPath returns the path of the execution history file
+func GetHistoryPath() string {
+	return filepath.Join(GetUserConfigDir(), "history.jsonl")
+}
+
+// GetFavoritesPath returns the path of the user's favorites file
+func GetFavoritesPath() string {
+	return filepath.Join(GetUserConfigDir(), "favorites.json")
+}
+
+// FindProjectScriptsDir returns the nearest .devlauncher/scripts folder found
+// walking up from startDir, or "" when there is none. The walk stops below
+// $HOME, so ~/.devlauncher (the default install dir) is never a project, and
+// installDir is skipped wherever it is.
+func FindProjectScriptsDir(startDir, installDir string) string {
+	if startDir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	if home != "" {
+		home, _ = filepath.Abs(home)
+	}
+	if installDir != "" {
+		installDir, _ = filepath.Abs(installDir)
+	}
+	for {
+		if home != "" && SamePath(dir, home) {
+			return ""
+		}
+		project := filepath.Join(dir, ".devlauncher")
+		candidate := filepath.Join(project, "scripts")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() && !SamePath(project, installDir) {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SamePath compares two paths, ignoring case on Windows
+func SamePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectScriptsDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := filepath.Join(home, "src", "app")
+	install := filepath.Join(home, "src", "other", ".devlauncher")
+	for _, dir := range []string{
+		filepath.Join(project, ".devlauncher", "scripts"),
+		filepath.Join(project, "cmd", "tool"),
+		filepath.Join(install, "scripts"),
+		filepath.Join(home, "src", "other", "sub"),
+		filepath.Join(home, ".devlauncher", "scripts"),
+		filepath.Join(home, "notes"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+	}{
+		{"project root", project, filepath.Join(project, ".devlauncher", "scripts")},
+		{"below the project", filepath.Join(project, "cmd", "tool"), filepath.Join(project, ".devlauncher", "scripts")},
+		{"install dir is not a project", filepath.Join(home, "src", "other", "sub"), ""},
+		{"stops below $HOME", filepath.Join(home, "notes"), ""},
+		{"no start dir", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindProjectScriptsDir(tt.start, install); got != tt.want {
+				t.Errorf("FindProjectScriptsDir(%q) = %q, want %q", tt.start, got, tt.want)
+			}
+		})
+	}
+}
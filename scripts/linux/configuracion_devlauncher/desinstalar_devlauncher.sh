@@ -1,6 +1,7 @@
 #!/bin/bash
 # Script: Desinstalar DevLauncher en Linux
 # Ejecuta el uninstaller instalado en el directorio de DevLauncher.
+# @dangerous
 
 set -e
 
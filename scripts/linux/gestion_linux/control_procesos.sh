@@ -2,6 +2,7 @@
 
 # Script de control y monitoreo de procesos
 # Permite ver, buscar, filtrar y gestionar procesos del sistema
+# @dangerous
 
 # Cargar librería común
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
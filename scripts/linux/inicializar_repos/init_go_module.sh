@@ -2,6 +2,7 @@
 
 # Script para inicializar un módulo simple de Go
 # Crea una carpeta module/ con estructura básica
+# @param MODULE_NAME "Nombre del módulo" default=module required env
 
 # Cargar librería común
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
@@ -11,7 +12,7 @@ set -e
 trap 'error "El script falló en la línea $LINENO"' ERR
 
 # Nombre del módulo
-MODULE_NAME="module"
+MODULE_NAME="${MODULE_NAME:-module}"
 
 show_header "Inicializador de Módulo Go 🐹" "Módulo simple y limpio"
 
@@ -2,6 +2,7 @@
 
 # Script para inicializar un proyecto Python con uv
 # Crea estructura simple con uv + venv
+# @param PROJECT_NAME "Nombre del proyecto" default=python-project required env
 
 # Cargar librería común
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
@@ -11,7 +12,7 @@ set -e
 trap 'error "El script falló en la línea $LINENO"' ERR
 
 # Nombre del proyecto
-PROJECT_NAME="python-project"
+PROJECT_NAME="${PROJECT_NAME:-python-project}"
 
 show_header "Inicializador de Proyecto Python 🐍" "uv + venv + estructura simple"
 
@@ -1,5 +1,6 @@
 # Script: Desinstalar DevLauncher en Windows
 # Ejecuta el uninstaller.exe instalado en el directorio de DevLauncher.
+# @dangerous
 
 $ErrorActionPreference = "Stop"
 
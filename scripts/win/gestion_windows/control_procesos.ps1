@@ -1,5 +1,6 @@
 # Script de control y monitoreo de procesos para Windows
 # Permite ver, buscar, filtrar y gestionar procesos del sistema
+# @dangerous
 
 $Green  = "`e[32m"
 $Yellow = "`e[33m"